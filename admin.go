@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func handlerListDeadLetters(ctx *gin.Context) {
+	deps := ctx.MustGet("deps").(*AppDependencies)
+	letters, err := deps.deadLetters.List()
+	if err != nil {
+		deps.logger.WithField("error", err).Error("couldn't list dead letters")
+		ctx.Status(CodeError)
+		return
+	}
+
+	ctx.JSON(CodeOK, letters)
+}
+
+// handlerRetryDeadLetter sends a dead letter again.  The old entry is removed
+// first; if the message fails again it's dead lettered under a new ID with a
+// fresh attempt history.
+func handlerRetryDeadLetter(ctx *gin.Context) {
+	deps := ctx.MustGet("deps").(*AppDependencies)
+	dl, err := deps.deadLetters.Get(ctx.Param("id"))
+	if err != nil {
+		deps.logger.WithField("error", err).Error("couldn't retrieve dead letter")
+		ctx.Status(CodeError)
+		return
+	}
+	if dl == nil {
+		ctx.JSON(CodeNotFound, fmt.Sprintf("{\"%v\":\"%v\"}", "reason", errNotFound))
+		return
+	}
+
+	err = deps.deadLetters.Delete(dl.ID)
+	if err != nil {
+		deps.logger.WithField("error", err).Error("couldn't remove dead letter")
+		ctx.Status(CodeError)
+		return
+	}
+
+	err = deps.dispatchers.ProcessChannelMessage(dl.Repo, dl.Channel, dl.Message, deps.logger)
+	if err != nil {
+		deps.logger.WithFields(logrus.Fields{
+			"error":       err,
+			"dead_letter": dl.ID,
+		}).Error("retry of dead letter failed")
+		ctx.JSON(CodeError, fmt.Sprintf("{\"%v\":\"%v\"}", "reason", err.Error()))
+		return
+	}
+
+	ctx.Status(CodeNoContent)
+}
+
+func handlerDeleteDeadLetter(ctx *gin.Context) {
+	deps := ctx.MustGet("deps").(*AppDependencies)
+	err := deps.deadLetters.Delete(ctx.Param("id"))
+	if err != nil {
+		deps.logger.WithField("error", err).Error("couldn't remove dead letter")
+		ctx.Status(CodeError)
+		return
+	}
+
+	ctx.Status(CodeNoContent)
+}
+
+func handlerPurgeDeadLetters(ctx *gin.Context) {
+	deps := ctx.MustGet("deps").(*AppDependencies)
+	err := deps.deadLetters.Purge()
+	if err != nil {
+		deps.logger.WithField("error", err).Error("couldn't purge dead letters")
+		ctx.Status(CodeError)
+		return
+	}
+
+	ctx.Status(CodeNoContent)
+}
+
+// handlerForgetDelivery is the override for deduplication: once a delivery
+// is forgotten, redelivering it from GitHub announces it again.
+func handlerForgetDelivery(ctx *gin.Context) {
+	deps := ctx.MustGet("deps").(*AppDependencies)
+	err := deps.deliveries.Forget(ctx.Param("delivery"))
+	if err != nil {
+		deps.logger.WithField("error", err).Error("couldn't forget delivery")
+		ctx.Status(CodeError)
+		return
+	}
+
+	ctx.Status(CodeNoContent)
+}
@@ -1,11 +1,21 @@
 package main
 
 import (
+	"github.com/mike-webster/repo-watcher/archive"
 	dispatchers "github.com/mike-webster/repo-watcher/dispatchers"
+	"github.com/mike-webster/repo-watcher/storage"
 	"github.com/sirupsen/logrus"
 )
 
 type AppDependencies struct {
 	logger      *logrus.Logger
 	dispatchers dispatchers.Dispatchers
+	queue       *workerPool
+	store       *storage.DB
+	deadLetters *dispatchers.DeadLetterStore
+	deliveries  *deliveryStore
+	archive     *archive.Store
+	ciStates    *ciStateStore
+	deployments *deploymentStore
+	reviews     *reviewStore
 }
@@ -0,0 +1,148 @@
+package archive
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mike-webster/repo-watcher/storage"
+	"github.com/mike-webster/repo-watcher/webhookmodels"
+)
+
+const bucket = "archive"
+
+const defaultLimit = 100
+
+// Delivery is a webhook delivery exactly as it was received.
+type Delivery struct {
+	ID         string          `json:"id"`
+	Provider   string          `json:"provider"`
+	Event      string          `json:"event"`
+	Repo       string          `json:"repo"`
+	Sender     string          `json:"sender"`
+	Action     string          `json:"action"`
+	ReceivedAt time.Time       `json:"received_at"`
+	Body       json.RawMessage `json:"body,omitempty"`
+}
+
+// Query narrows down which deliveries are returned by Find.  Empty fields
+// match everything.
+type Query struct {
+	Repo   string
+	Event  string
+	Action string
+	Sender string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+func (q *Query) matches(d *Delivery) bool {
+	if len(q.Repo) > 0 && !strings.EqualFold(q.Repo, d.Repo) {
+		return false
+	}
+	if len(q.Event) > 0 && q.Event != d.Event {
+		return false
+	}
+	if len(q.Action) > 0 && q.Action != d.Action {
+		return false
+	}
+	if len(q.Sender) > 0 && !strings.EqualFold(q.Sender, d.Sender) {
+		return false
+	}
+	if !q.Since.IsZero() && d.ReceivedAt.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && d.ReceivedAt.After(q.Until) {
+		return false
+	}
+
+	return true
+}
+
+// Store keeps every accepted delivery on disk.
+type Store struct {
+	DB *storage.DB
+}
+
+// NewDelivery builds a delivery from the parsed event and its raw body.
+func NewDelivery(provider string, id string, eventName string, event webhookmodels.Event, body []byte) *Delivery {
+	sBody := struct {
+		Action           string `json:"action"`
+		ObjectAttributes struct {
+			Action string `json:"action"`
+		} `json:"object_attributes"`
+	}{}
+	// whatever doesn't decode is just left blank
+	json.Unmarshal(body, &sBody)
+
+	action := sBody.Action
+	if len(action) < 1 {
+		action = sBody.ObjectAttributes.Action
+	}
+
+	return &Delivery{
+		ID:         id,
+		Provider:   provider,
+		Event:      eventName,
+		Repo:       event.Repository(),
+		Sender:     event.Username(),
+		Action:     action,
+		ReceivedAt: time.Now(),
+		Body:       json.RawMessage(body),
+	}
+}
+
+// Save stores the delivery, replacing any earlier copy with the same ID.
+func (s *Store) Save(d *Delivery) error {
+	return s.DB.Put(bucket, d.ID, d)
+}
+
+// Get returns the delivery with the ID, including its body, or nil if it
+// isn't in the archive.
+func (s *Store) Get(id string) (*Delivery, error) {
+	var d Delivery
+	found, err := s.DB.Get(bucket, id, &d)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+// Find returns the deliveries matching the query, newest first.  Bodies are
+// left off; use Get for the full payload.
+func (s *Store) Find(q Query) ([]Delivery, error) {
+	ret := []Delivery{}
+	err := s.DB.ForEach(bucket, func(key string, raw []byte) error {
+		var d Delivery
+		err := json.Unmarshal(raw, &d)
+		if err != nil {
+			return err
+		}
+
+		if q.matches(&d) {
+			d.Body = nil
+			ret = append(ret, d)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].ReceivedAt.After(ret[j].ReceivedAt)
+	})
+
+	limit := q.Limit
+	if limit < 1 {
+		limit = defaultLimit
+	}
+	if len(ret) > limit {
+		ret = ret[:limit]
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	env "github.com/mike-webster/repo-watcher/env"
+	webhookmodels "github.com/mike-webster/repo-watcher/webhookmodels"
+)
+
+type bbRequestHeader struct {
+	Event     string `header:"X-Event-Key" binding:"required"`
+	Signature string `header:"X-Hub-Signature" binding:"required"`
+	Delivery  string `header:"X-Request-Id"`
+}
+
+// bitbucketProvider receives webhooks from Bitbucket Server / Data Center.
+type bitbucketProvider struct{}
+
+func (bbp *bitbucketProvider) Name() string {
+	return providerBitbucket
+}
+
+func (bbp *bitbucketProvider) Headers(ctx *gin.Context) (string, string, error) {
+	hdr := &bbRequestHeader{}
+	err := ctx.BindHeader(hdr)
+	if err != nil {
+		return "", "", err
+	}
+
+	return hdr.Event, hdr.Delivery, nil
+}
+
+// Repo pulls the "PROJECT/slug" name out of a raw webhook body so the
+// matching watcher can be found before the payload is trusted.
+func (bbp *bitbucketProvider) Repo(body []byte) string {
+	sBody := struct {
+		Repository  *webhookmodels.BitbucketRepository `json:"repository"`
+		PullRequest struct {
+			ToRef webhookmodels.BitbucketRef `json:"toRef"`
+		} `json:"pullRequest"`
+	}{}
+
+	err := json.Unmarshal(body, &sBody)
+	if err != nil {
+		return ""
+	}
+
+	if sBody.Repository != nil {
+		return sBody.Repository.FullName()
+	}
+	return sBody.PullRequest.ToRef.Repository.FullName()
+}
+
+// Verify checks the body signature.  Bitbucket server only signs with
+// sha256, but sends it in the older X-Hub-Signature header.
+func (bbp *bitbucketProvider) Verify(ctx *gin.Context, w *env.Watcher, body []byte) error {
+	return verifySignature(w, body, ctx.GetHeader("X-Hub-Signature"), "")
+}
+
+// Payload switches on the X-Event-Key.  Every pr:* key shares a payload.
+func (bbp *bitbucketProvider) Payload(eventKey string) (webhookmodels.Event, bool) {
+	switch {
+	case eventKey == "repo:refs_changed":
+		return &webhookmodels.BitbucketRefsChangedEventPayload{}, true
+	case strings.HasPrefix(eventKey, "pr:"):
+		return &webhookmodels.BitbucketPullRequestEventPayload{}, true
+	case eventKey == "diagnostics:ping":
+		// sent from the "Test connection" button
+		return nil, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	webhookmodels "github.com/mike-webster/repo-watcher/webhookmodels"
+)
+
+var (
+	// reMergeCommit matches the commit left by the merge button
+	reMergeCommit = regexp.MustCompile(`^Merge pull request #(\d+) from (\S+)`)
+	// reSquashCommit matches the commit left by squash and rebase merges
+	reSquashCommit = regexp.MustCompile(`^(.+) \(#(\d+)\)$`)
+)
+
+type apiRelease struct {
+	TagName string `json:"tag_name"`
+	Draft   bool   `json:"draft"`
+}
+
+type apiCompare struct {
+	TotalCommits int `json:"total_commits"`
+	Commits      []struct {
+		Commit struct {
+			Message string `json:"message"`
+		} `json:"commit"`
+	} `json:"commits"`
+}
+
+// releaseChangelog lists the pull requests merged between the release
+// before tag and tag itself.  The first release of a repo has no changelog.
+func releaseChangelog(baseURL string, token string, repo string, tag string) ([]webhookmodels.ChangelogEntry, error) {
+	body, err := MakeRequest(fmt.Sprintf("%s/repos/%s/releases?per_page=100", baseURL, repo), "", token)
+	if err != nil {
+		return nil, err
+	}
+	var releases []apiRelease
+	err = json.Unmarshal(*body, &releases)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := previousTag(releases, tag)
+	if len(previous) < 1 {
+		return nil, nil
+	}
+
+	body, err = MakeRequest(fmt.Sprintf("%s/repos/%s/compare/%s...%s", baseURL, repo, url.PathEscape(previous), url.PathEscape(tag)), "", token)
+	if err != nil {
+		return nil, err
+	}
+	var compare apiCompare
+	err = json.Unmarshal(*body, &compare)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []string{}
+	for _, c := range compare.Commits {
+		messages = append(messages, c.Commit.Message)
+	}
+	return changelogEntries(messages), nil
+}
+
+// compareSize returns how many commits are between before and after.
+func compareSize(baseURL string, token string, repo string, before string, after string) (int, error) {
+	body, err := MakeRequest(fmt.Sprintf("%s/repos/%s/compare/%s...%s", baseURL, repo, before, after), "", token)
+	if err != nil {
+		return 0, err
+	}
+	var compare apiCompare
+	err = json.Unmarshal(*body, &compare)
+	if err != nil {
+		return 0, err
+	}
+
+	return compare.TotalCommits, nil
+}
+
+// previousTag returns the tag of the published release that came before
+// tag.  Releases are listed newest first.
+func previousTag(releases []apiRelease, tag string) string {
+	seen := false
+	for _, r := range releases {
+		if r.TagName == tag {
+			seen = true
+			continue
+		}
+		if seen && !r.Draft {
+			return r.TagName
+		}
+	}
+
+	if seen {
+		return ""
+	}
+	// the new release isn't listed yet, so the newest one is the previous
+	for _, r := range releases {
+		if !r.Draft {
+			return r.TagName
+		}
+	}
+	return ""
+}
+
+// changelogEntries picks the pull requests out of the commit messages,
+// oldest first.
+func changelogEntries(messages []string) []webhookmodels.ChangelogEntry {
+	ret := []webhookmodels.ChangelogEntry{}
+	seen := map[int]bool{}
+	for _, message := range messages {
+		lines := strings.Split(strings.TrimSpace(message), "\n")
+
+		var (
+			number int
+			title  string
+		)
+		if m := reMergeCommit.FindStringSubmatch(lines[0]); m != nil {
+			number, _ = strconv.Atoi(m[1])
+			title = m[2]
+			// the pull request title is the first line of the body
+			for _, l := range lines[1:] {
+				if len(strings.TrimSpace(l)) > 0 {
+					title = strings.TrimSpace(l)
+					break
+				}
+			}
+		} else if m := reSquashCommit.FindStringSubmatch(strings.TrimSpace(lines[0])); m != nil {
+			number, _ = strconv.Atoi(m[2])
+			title = m[1]
+		}
+
+		if number < 1 || seen[number] {
+			continue
+		}
+		seen[number] = true
+		ret = append(ret, webhookmodels.ChangelogEntry{Number: number, Title: title})
+	}
+
+	return ret
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mike-webster/repo-watcher/storage"
+	webhookmodels "github.com/mike-webster/repo-watcher/webhookmodels"
+)
+
+const ciStateBucket = "ci_states"
+
+// ciStateTTL is how long a failure is remembered.  Branches that are never
+// fixed, usually because they were abandoned, don't stay red forever.
+const ciStateTTL = 30 * 24 * time.Hour
+
+type ciFailure struct {
+	SHA          string    `json:"sha"`
+	PullRequests []int     `json:"pull_requests"`
+	FailedAt     time.Time `json:"failed_at"`
+}
+
+// ciStateStore remembers which checks are red so the next green result for
+// the same check and branch can be announced as a recovery.
+type ciStateStore struct {
+	db *storage.DB
+	mu sync.Mutex
+}
+
+func newCIStateStore(db *storage.DB) *ciStateStore {
+	return &ciStateStore{db: db}
+}
+
+// Record tracks the result.  When a passing result clears an earlier
+// failure, the failure is returned.
+func (cs *ciStateStore) Record(result *webhookmodels.CIResult) (*ciFailure, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	key := ciStateKey(result)
+	switch result.State {
+	case webhookmodels.CIFailure:
+		return nil, cs.db.Put(ciStateBucket, key, ciFailure{
+			SHA:          result.SHA,
+			PullRequests: result.PullRequests,
+			FailedAt:     time.Now(),
+		})
+	case webhookmodels.CISuccess:
+		var failure ciFailure
+		found, err := cs.db.Get(ciStateBucket, key, &failure)
+		if err != nil || !found {
+			return nil, err
+		}
+		return &failure, cs.db.Delete(ciStateBucket, key)
+	}
+
+	return nil, nil
+}
+
+// Prune removes failures older than the TTL and returns how many were
+// removed.
+func (cs *ciStateStore) Prune() (int, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	expired := []string{}
+	err := cs.db.ForEach(ciStateBucket, func(key string, raw []byte) error {
+		var failure ciFailure
+		if err := json.Unmarshal(raw, &failure); err != nil || time.Since(failure.FailedAt) >= ciStateTTL {
+			expired = append(expired, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, key := range expired {
+		if err := cs.db.Delete(ciStateBucket, key); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(expired), nil
+}
+
+// ciStateKey identifies a check on a branch.  Results that don't say which
+// branch they're for are tracked by commit.
+func ciStateKey(result *webhookmodels.CIResult) string {
+	ref := result.SHA
+	if len(result.Branches) > 0 {
+		ref = result.Branches[0]
+	}
+	return strings.ToLower(fmt.Sprint(result.Repo, "|", result.Context, "|", ref))
+}
+
+type apiPullRequest struct {
+	Number int `json:"number"`
+	User   struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+// pullRequestAuthor returns the login of whoever opened the pull request the
+// result is for.  When the event didn't say which pull request that is,
+// it's looked up from the commit.
+func pullRequestAuthor(baseURL string, token string, result *webhookmodels.CIResult, prs []int) (string, error) {
+	var pr apiPullRequest
+	if len(prs) > 0 {
+		body, err := MakeRequest(fmt.Sprintf("%s/repos/%s/pulls/%d", baseURL, result.Repo, prs[0]), "", token)
+		if err != nil {
+			return "", err
+		}
+		err = json.Unmarshal(*body, &pr)
+		if err != nil {
+			return "", err
+		}
+		return pr.User.Login, nil
+	}
+
+	body, err := MakeRequest(fmt.Sprintf("%s/repos/%s/commits/%s/pulls", baseURL, result.Repo, result.SHA), "", token)
+	if err != nil {
+		return "", err
+	}
+	var found []apiPullRequest
+	err = json.Unmarshal(*body, &found)
+	if err != nil || len(found) < 1 {
+		return "", err
+	}
+	return found[0].User.Login, nil
+}
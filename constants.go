@@ -3,6 +3,9 @@ package main
 // CodeOK is for a 200 response
 const CodeOK int = 200
 
+// CodeAccepted is for a 202 response
+const CodeAccepted int = 202
+
 // CodeNoContent is for a 204 response
 const CodeNoContent int = 204
 
@@ -12,7 +15,28 @@ const CodeInvalid int = 400
 // CodeUnauth is for a 401 response
 const CodeUnauth int = 401
 
+// CodeNotFound is for a 404 response
+const CodeNotFound int = 404
+
+// CodeError is for a 500 response
+const CodeError int = 500
+
+// CodeUnavailable is for a 503 response
+const CodeUnavailable int = 503
+
+const providerGitHub = "github"
+const providerGitLab = "gitlab"
+const providerBitbucket = "bitbucket"
+const providerGitea = "gitea"
+
+const defaultWorkers = 4
+const defaultQueueSize = 100
+
 const errInvalidSecret = "Invalid request secret"
 const errMissingEvent = "Missing event value"
 const errInvalidBody = "Invalid POST body"
 const errInvalidHeader = "Invalid request headers"
+const errNotFound = "Not found"
+const errQueueFull = "Too many events waiting to be processed"
+
+const msgDuplicateDelivery = "Delivery already processed"
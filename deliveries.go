@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/mike-webster/repo-watcher/storage"
+)
+
+const deliveryBucket = "deliveries"
+
+const defaultDeliveryTTL = 72 * time.Hour
+
+type deliveryRecord struct {
+	ID     string    `json:"id"`
+	SeenAt time.Time `json:"seen_at"`
+}
+
+// deliveryStore remembers which deliveries have already been accepted so a
+// redelivery of the same event isn't announced twice.
+type deliveryStore struct {
+	db  *storage.DB
+	ttl time.Duration
+	mu  sync.Mutex
+}
+
+func newDeliveryStore(db *storage.DB, ttl time.Duration) *deliveryStore {
+	if ttl <= 0 {
+		ttl = defaultDeliveryTTL
+	}
+
+	return &deliveryStore{db: db, ttl: ttl}
+}
+
+// Claim records the delivery and returns true, unless it was already seen
+// within the TTL, in which case it returns false.
+func (ds *deliveryStore) Claim(id string) (bool, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	var rec deliveryRecord
+	found, err := ds.db.Get(deliveryBucket, id, &rec)
+	if err != nil {
+		return false, err
+	}
+	if found && time.Since(rec.SeenAt) < ds.ttl {
+		return false, nil
+	}
+
+	return true, ds.db.Put(deliveryBucket, id, deliveryRecord{ID: id, SeenAt: time.Now()})
+}
+
+// Forget removes the delivery so the next redelivery is processed again.
+func (ds *deliveryStore) Forget(id string) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return ds.db.Delete(deliveryBucket, id)
+}
+
+// Prune removes every delivery older than the TTL and returns how many were
+// removed.
+func (ds *deliveryStore) Prune() (int, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	expired := []string{}
+	err := ds.db.ForEach(deliveryBucket, func(key string, raw []byte) error {
+		var rec deliveryRecord
+		if err := json.Unmarshal(raw, &rec); err != nil || time.Since(rec.SeenAt) >= ds.ttl {
+			expired = append(expired, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range expired {
+		if err := ds.db.Delete(deliveryBucket, id); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(expired), nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mike-webster/repo-watcher/storage"
+	webhookmodels "github.com/mike-webster/repo-watcher/webhookmodels"
+)
+
+const deploymentBucket = "deployments"
+
+// deploymentTTL is how long a deployment's states are kept after its last
+// status.
+const deploymentTTL = 7 * 24 * time.Hour
+
+type deploymentRecord struct {
+	States    []string  `json:"states"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// deploymentStore remembers the states each deployment has been through so
+// its statuses can be announced as one story, once per state change.
+type deploymentStore struct {
+	db *storage.DB
+	mu sync.Mutex
+}
+
+func newDeploymentStore(db *storage.DB) *deploymentStore {
+	return &deploymentStore{db: db}
+}
+
+// Record adds the status's state to its deployment's history and returns the
+// states before it.  It returns false when the deployment is already in
+// that state.
+func (ds *deploymentStore) Record(e *webhookmodels.DeploymentStatusEventPayload) ([]string, bool, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	key := strings.ToLower(fmt.Sprint(e.Repo.Slug(), "|", e.Deployment.ID))
+	var rec deploymentRecord
+	_, err := ds.db.Get(deploymentBucket, key, &rec)
+	if err != nil {
+		return nil, false, err
+	}
+
+	state := e.DeploymentStatus.State
+	if len(rec.States) > 0 && rec.States[len(rec.States)-1] == state {
+		return rec.States[:len(rec.States)-1], false, nil
+	}
+
+	previous := rec.States
+	rec.States = append(append([]string{}, rec.States...), state)
+	rec.UpdatedAt = time.Now()
+	return previous, true, ds.db.Put(deploymentBucket, key, rec)
+}
+
+// Prune removes deployments that haven't changed within the TTL and returns
+// how many were removed.
+func (ds *deploymentStore) Prune() (int, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	expired := []string{}
+	err := ds.db.ForEach(deploymentBucket, func(key string, raw []byte) error {
+		var rec deploymentRecord
+		if err := json.Unmarshal(raw, &rec); err != nil || time.Since(rec.UpdatedAt) >= deploymentTTL {
+			expired = append(expired, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, key := range expired {
+		if err := ds.db.Delete(deploymentBucket, key); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(expired), nil
+}
@@ -0,0 +1,90 @@
+package dispatchers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mike-webster/repo-watcher/storage"
+)
+
+const deadLetterBucket = "dead_letters"
+
+// Attempt records a single try at sending a message.
+type Attempt struct {
+	At    time.Time `json:"at"`
+	Error string    `json:"error"`
+}
+
+// DeadLetter is a message that couldn't be delivered after every retry.
+type DeadLetter struct {
+	ID        string    `json:"id"`
+	Repo      string    `json:"repo"`
+	Channel   string    `json:"channel,omitempty"`
+	Message   string    `json:"message"`
+	Error     string    `json:"error"`
+	Attempts  []Attempt `json:"attempts"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DeadLetterStore keeps undeliverable messages around so they can be
+// inspected and retried later.
+type DeadLetterStore struct {
+	DB *storage.DB
+}
+
+// Save stores the dead letter, assigning it an ID if it doesn't have one.
+func (dls *DeadLetterStore) Save(dl *DeadLetter) error {
+	if len(dl.ID) < 1 {
+		seq, err := dls.DB.NextSequence(deadLetterBucket)
+		if err != nil {
+			return err
+		}
+		// nanosecond timestamps keep the bucket in the order things failed,
+		// the sequence keeps two failures in the same instant apart
+		dl.ID = fmt.Sprintf("%d-%d", time.Now().UnixNano(), seq)
+	}
+	if dl.CreatedAt.IsZero() {
+		dl.CreatedAt = time.Now()
+	}
+
+	return dls.DB.Put(deadLetterBucket, dl.ID, dl)
+}
+
+// List returns every dead letter, oldest first.
+func (dls *DeadLetterStore) List() ([]DeadLetter, error) {
+	ret := []DeadLetter{}
+	err := dls.DB.ForEach(deadLetterBucket, func(key string, raw []byte) error {
+		var dl DeadLetter
+		err := json.Unmarshal(raw, &dl)
+		if err != nil {
+			return err
+		}
+
+		ret = append(ret, dl)
+		return nil
+	})
+
+	return ret, err
+}
+
+// Get returns the dead letter with the ID, or nil if there isn't one.
+func (dls *DeadLetterStore) Get(id string) (*DeadLetter, error) {
+	var dl DeadLetter
+	found, err := dls.DB.Get(deadLetterBucket, id, &dl)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &dl, nil
+}
+
+// Delete removes the dead letter with the ID.
+func (dls *DeadLetterStore) Delete(id string) error {
+	return dls.DB.Delete(deadLetterBucket, id)
+}
+
+// Purge removes every dead letter.
+func (dls *DeadLetterStore) Purge() error {
+	return dls.DB.Purge(deadLetterBucket)
+}
@@ -13,13 +13,51 @@ type Dispatcher interface {
 	SendMessage(string, *logrus.Logger) error
 }
 
+// channeled is implemented by dispatchers that send to one of a repo's named
+// channels instead of its default webhook.
+type channeled interface {
+	Channel() string
+}
+
+// channelOf returns the dispatcher's named channel, or an empty string for
+// the repo's default webhook.
+func channelOf(d Dispatcher) string {
+	if c, ok := d.(channeled); ok {
+		return c.Channel()
+	}
+	return ""
+}
+
 type Dispatchers []Dispatcher
 
 func (d *Dispatchers) ProcessMessage(repo string, message string, logger *logrus.Logger) error {
+	return d.ProcessChannelMessage(repo, "", message, logger)
+}
+
+// ProcessChannelMessage sends the message to the repo's named channel.  It
+// falls back to the repo's default webhook when the channel isn't configured.
+func (d *Dispatchers) ProcessChannelMessage(repo string, channel string, message string, logger *logrus.Logger) error {
+	var fallback Dispatcher
 	for _, i := range *d {
-		if strings.ToLower(i.Repo()) == strings.ToLower(repo) {
+		if strings.ToLower(i.Repo()) != strings.ToLower(repo) {
+			continue
+		}
+		c := channelOf(i)
+		if strings.ToLower(c) == strings.ToLower(channel) {
 			return i.SendMessage(message, logger)
 		}
+		if len(c) < 1 && fallback == nil {
+			fallback = i
+		}
+	}
+
+	if fallback != nil {
+		logger.WithFields(logrus.Fields{
+			"event":   "unknown_channel",
+			"repo":    repo,
+			"channel": channel,
+		}).Warn("channel isn't configured, sending to the repo's webhook")
+		return fallback.SendMessage(message, logger)
 	}
 
 	return errors.New(fmt.Sprint("couldnt find dispatcher to match repo: ", repo))
@@ -0,0 +1,35 @@
+package dispatchers
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/sirupsen/logrus"
+)
+
+func TestProcessChannelMessage(t *testing.T) {
+	logger := logrus.New()
+	def := &TestDispatcher{RepoName: "test"}
+	qa := &TestDispatcher{RepoName: "test", ChannelName: "qa"}
+	ds := Dispatchers{qa, &RetryDispatcher{Dispatcher: def}}
+
+	t.Run("NamedChannel", func(t *testing.T) {
+		assert.Equal(t, nil, ds.ProcessChannelMessage("TEST", "QA", "question", logger))
+		assert.Equal(t, "question", qa.MessageSent)
+		assert.Equal(t, "", def.MessageSent)
+	})
+
+	t.Run("DefaultWebhook", func(t *testing.T) {
+		assert.Equal(t, nil, ds.ProcessMessage("test", "push", logger))
+		assert.Equal(t, "push", def.MessageSent)
+	})
+
+	t.Run("UnknownChannel", func(t *testing.T) {
+		assert.Equal(t, nil, ds.ProcessChannelMessage("test", "security", "alert", logger))
+		assert.Equal(t, "alert", def.MessageSent)
+	})
+
+	t.Run("UnknownRepo", func(t *testing.T) {
+		assert.NotEqual(t, nil, ds.ProcessChannelMessage("other", "", "push", logger))
+	})
+}
@@ -0,0 +1,135 @@
+package dispatchers
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultMaxAttempts = 5
+	defaultBaseDelay   = time.Second
+	defaultMaxDelay    = 30 * time.Second
+)
+
+// RetryDispatcher wraps another dispatcher, retrying failed sends with a
+// jittered exponential backoff.  Retries are scheduled on a timer instead of
+// waited out, so a dead webhook doesn't hold up the worker that sent to it.
+// Messages that still can't be sent are written to the dead letter store.
+type RetryDispatcher struct {
+	Dispatcher
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	DeadLetters *DeadLetterStore
+
+	// after is swapped out in tests
+	after func(time.Duration, func())
+}
+
+// Channel returns the wrapped dispatcher's channel.
+func (rd *RetryDispatcher) Channel() string {
+	return channelOf(rd.Dispatcher)
+}
+
+// SendMessage sends the message through the wrapped dispatcher.  It only
+// returns an error when the first attempt fails and won't be retried; later
+// failures are logged and dead lettered.
+func (rd *RetryDispatcher) SendMessage(message string, logger *logrus.Logger) error {
+	return rd.attempt(message, []Attempt{}, logger)
+}
+
+// attempt makes one try at sending the message, scheduling the next one if
+// the error is worth retrying and there are attempts left.
+func (rd *RetryDispatcher) attempt(message string, attempts []Attempt, logger *logrus.Logger) error {
+	maxAttempts := rd.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = defaultMaxAttempts
+	}
+	after := rd.after
+	if after == nil {
+		after = func(d time.Duration, fn func()) { time.AfterFunc(d, fn) }
+	}
+
+	err := rd.Dispatcher.SendMessage(message, logger)
+	if err == nil {
+		return nil
+	}
+	attempts = append(attempts, Attempt{At: time.Now(), Error: err.Error()})
+
+	he, ok := err.(*HTTPError)
+	if (ok && !he.Retryable()) || len(attempts) >= maxAttempts {
+		return rd.deadLetter(message, attempts, err, logger)
+	}
+
+	wait := rd.backoff(len(attempts) - 1)
+	if ok && he.Code == 429 && he.RetryAfter > 0 {
+		wait = he.RetryAfter
+	}
+
+	logger.WithFields(logrus.Fields{
+		"event":   "dispatch_retry",
+		"error":   err,
+		"repo":    rd.Repo(),
+		"attempt": len(attempts),
+		"wait":    wait.String(),
+	}).Warn("couldn't send message, retrying")
+	after(wait, func() {
+		if err := rd.attempt(message, attempts, logger); err != nil {
+			logger.WithFields(logrus.Fields{
+				"event": "dispatch_failed",
+				"error": err,
+				"repo":  rd.Repo(),
+			}).Error("error sending message")
+		}
+	})
+	return nil
+}
+
+// deadLetter saves the message once every attempt has failed.
+func (rd *RetryDispatcher) deadLetter(message string, attempts []Attempt, err error, logger *logrus.Logger) error {
+	if rd.DeadLetters != nil {
+		dl := &DeadLetter{
+			Repo:     rd.Repo(),
+			Channel:  rd.Channel(),
+			Message:  message,
+			Error:    err.Error(),
+			Attempts: attempts,
+		}
+		if dlErr := rd.DeadLetters.Save(dl); dlErr != nil {
+			logger.WithFields(logrus.Fields{
+				"event": "dead_letter_failed",
+				"error": dlErr,
+				"repo":  rd.Repo(),
+			}).Error("couldn't save dead letter, message is lost")
+		} else {
+			return fmt.Errorf("gave up after %d attempts, saved dead letter %s: %v", len(attempts), dl.ID, err)
+		}
+	}
+
+	return fmt.Errorf("gave up after %d attempts: %v", len(attempts), err)
+}
+
+// backoff returns how long to wait after the given (zero based) attempt.
+// Half of the delay is fixed and half is random so a burst of failures
+// doesn't retry in lockstep.
+func (rd *RetryDispatcher) backoff(attempt int) time.Duration {
+	base := rd.BaseDelay
+	if base <= 0 {
+		base = defaultBaseDelay
+	}
+	max := rd.MaxDelay
+	if max <= 0 {
+		max = defaultMaxDelay
+	}
+
+	delay := base << uint(attempt)
+	if delay > max || delay <= 0 {
+		delay = max
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
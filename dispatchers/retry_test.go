@@ -0,0 +1,128 @@
+package dispatchers
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	"github.com/mike-webster/repo-watcher/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// flakyDispatcher fails with the configured errors in order, then succeeds.
+type flakyDispatcher struct {
+	errs    []error
+	calls   int
+	channel string
+}
+
+func (fd *flakyDispatcher) Repo() string {
+	return "test"
+}
+
+func (fd *flakyDispatcher) Channel() string {
+	return fd.channel
+}
+
+func (fd *flakyDispatcher) SendMessage(message string, logger *logrus.Logger) error {
+	fd.calls++
+	if fd.calls <= len(fd.errs) {
+		return fd.errs[fd.calls-1]
+	}
+	return nil
+}
+
+func TestRetryDispatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dispatchers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := storage.Open(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	store := &DeadLetterStore{DB: db}
+	logger := logrus.New()
+
+	t.Run("RecoversAfterRetry", func(t *testing.T) {
+		waits := []time.Duration{}
+		fd := &flakyDispatcher{errs: []error{errors.New("boom"), &HTTPError{Code: 502}}}
+		rd := &RetryDispatcher{Dispatcher: fd, DeadLetters: store, after: func(d time.Duration, fn func()) { waits = append(waits, d); fn() }}
+
+		assert.Equal(t, nil, rd.SendMessage("hello", logger))
+		assert.Equal(t, 3, fd.calls)
+		assert.Equal(t, 2, len(waits))
+	})
+
+	t.Run("HonoursRetryAfter", func(t *testing.T) {
+		waits := []time.Duration{}
+		fd := &flakyDispatcher{errs: []error{&HTTPError{Code: 429, RetryAfter: 7 * time.Second}}}
+		rd := &RetryDispatcher{Dispatcher: fd, DeadLetters: store, after: func(d time.Duration, fn func()) { waits = append(waits, d); fn() }}
+
+		assert.Equal(t, nil, rd.SendMessage("hello", logger))
+		assert.Equal(t, []time.Duration{7 * time.Second}, waits)
+	})
+
+	t.Run("DeadLettersAfterLastAttempt", func(t *testing.T) {
+		fd := &flakyDispatcher{errs: []error{errors.New("one"), errors.New("two"), errors.New("three")}, channel: "qa"}
+		rd := &RetryDispatcher{Dispatcher: fd, MaxAttempts: 3, DeadLetters: store, after: func(_ time.Duration, fn func()) { fn() }}
+
+		// only the first attempt is made on the caller's goroutine
+		assert.Equal(t, nil, rd.SendMessage("hello", logger))
+		letters, err := store.List()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 1, len(letters))
+		assert.Equal(t, "hello", letters[0].Message)
+		assert.Equal(t, "qa", letters[0].Channel)
+		assert.Equal(t, "three", letters[0].Error)
+		assert.Equal(t, 3, len(letters[0].Attempts))
+		assert.Equal(t, nil, store.Purge())
+	})
+
+	t.Run("DoesNotWait", func(t *testing.T) {
+		var retry func()
+		fd := &flakyDispatcher{errs: []error{errors.New("boom")}}
+		rd := &RetryDispatcher{Dispatcher: fd, DeadLetters: store, after: func(_ time.Duration, fn func()) { retry = fn }}
+
+		assert.Equal(t, nil, rd.SendMessage("hello", logger))
+		assert.Equal(t, 1, fd.calls)
+
+		retry()
+		assert.Equal(t, 2, fd.calls)
+		letters, err := store.List()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 0, len(letters))
+	})
+
+	t.Run("StopsOnClientError", func(t *testing.T) {
+		fd := &flakyDispatcher{errs: []error{&HTTPError{Code: 404}, errors.New("never")}}
+		rd := &RetryDispatcher{Dispatcher: fd, DeadLetters: store, after: func(_ time.Duration, fn func()) { fn() }}
+
+		assert.NotEqual(t, nil, rd.SendMessage("hello", logger))
+		assert.Equal(t, 1, fd.calls)
+		assert.Equal(t, nil, store.Purge())
+	})
+
+	t.Run("DeadLetterIDsAreUnique", func(t *testing.T) {
+		for i := 0; i < 50; i++ {
+			assert.Equal(t, nil, store.Save(&DeadLetter{Repo: "test", Message: "same instant"}))
+		}
+		letters, err := store.List()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 50, len(letters))
+		assert.Equal(t, nil, store.Purge())
+	})
+
+	t.Run("BackoffIsBounded", func(t *testing.T) {
+		rd := &RetryDispatcher{BaseDelay: time.Second, MaxDelay: 4 * time.Second}
+		for i := 0; i < 10; i++ {
+			d := rd.backoff(i)
+			assert.Equal(t, true, d <= 4*time.Second)
+			assert.Equal(t, true, d >= 500*time.Millisecond)
+		}
+	})
+}
@@ -6,20 +6,29 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
 type SlackDispatcher struct {
 	RepoName string
-	URL      string
+	// ChannelName is the repo's named channel the webhook posts to, empty for
+	// the repo's default webhook.
+	ChannelName string
+	URL         string
 }
 
 func (sd *SlackDispatcher) Repo() string {
 	return sd.RepoName
 }
 
+func (sd *SlackDispatcher) Channel() string {
+	return sd.ChannelName
+}
+
 func (sd *SlackDispatcher) SendMessage(message string, logger *logrus.Logger) error {
 	// these characters need to be escaped for slack
 	// https://api.slack.com/reference/surfaces/formatting#escaping
@@ -47,11 +56,40 @@ func (sd *SlackDispatcher) SendMessage(message string, logger *logrus.Logger) er
 			"url":  sd.URL,
 		}).Error("non-200 response from extrnal call")
 
-		return errors.New(fmt.Sprint("non-200 response: ", resp.StatusCode))
+		return &HTTPError{
+			Code:       resp.StatusCode,
+			RetryAfter: retryAfter(resp.Header.Get("Retry-After")),
+		}
 	}
 	return nil
 }
 
+// HTTPError is returned when Slack responds with something other than a 200.
+type HTTPError struct {
+	Code int
+	// RetryAfter is how long Slack asked us to wait before trying again, if
+	// it said.
+	RetryAfter time.Duration
+}
+
+func (he *HTTPError) Error() string {
+	return fmt.Sprint("non-200 response: ", he.Code)
+}
+
+// Retryable reports whether sending the same message again could work.
+func (he *HTTPError) Retryable() bool {
+	return he.Code == 429 || he.Code >= 500
+}
+
+func retryAfter(header string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(header))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
 func getBlockKitText(text string, logger *logrus.Logger) string {
 	type slackText struct {
 		Type string `json:"type"`
@@ -8,6 +8,7 @@ import (
 
 type TestDispatcher struct {
 	RepoName    string
+	ChannelName string
 	MessageSent string
 	ShouldError bool
 	MakeCalls   bool
@@ -18,6 +19,10 @@ func (td *TestDispatcher) Repo() string {
 	return td.RepoName
 }
 
+func (td *TestDispatcher) Channel() string {
+	return td.ChannelName
+}
+
 func (td *TestDispatcher) SendMessage(message string, logger *logrus.Logger) error {
 	if td.ShouldError {
 		return errors.New("configured error")
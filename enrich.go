@@ -0,0 +1,113 @@
+package main
+
+import (
+	env "github.com/mike-webster/repo-watcher/env"
+	webhookmodels "github.com/mike-webster/repo-watcher/webhookmodels"
+	"github.com/sirupsen/logrus"
+)
+
+// enrichEvent fills in the parts of a payload that come from the watcher's
+// config or have to be looked up on the GHE API before it can be rendered.
+// A failed lookup is logged and the event is announced without it.
+func enrichEvent(event webhookmodels.Event, logger *logrus.Logger) {
+	cfg := env.GetConfig()
+	w := cfg.Watchers.Select(event.Repository())
+	switch e := event.(type) {
+	case *webhookmodels.PushEventPayload:
+		e.CommitLimit = cfg.CommitLimit
+		e.Protected = w != nil && w.Protects(e.Ref)
+		if e.Size > 0 || len(e.Commits) < webhookmodels.PayloadCommitCap || e.Created || e.Deleted {
+			return
+		}
+
+		token, err := apiToken()
+		if err == nil {
+			e.Size, err = compareSize(cfg.BaseURL(), token, e.Repo.Slug(), e.Before, e.After)
+		}
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"event": "failed_enrichment",
+				"error": err,
+				"repo":  e.Repository(),
+				"ref":   e.Ref,
+			}).Warn("couldn't count the pushed commits")
+		}
+	case *webhookmodels.DeleteEventPayload:
+		e.Protected = w != nil && w.Protects(e.Ref)
+	case *webhookmodels.ReleaseEventPayload:
+		if len(e.Release.Body) > 0 || len(e.ToString()) < 1 {
+			return
+		}
+
+		token, err := apiToken()
+		if err == nil {
+			e.Changelog, err = releaseChangelog(cfg.BaseURL(), token, e.Repo.Slug(), e.Release.TagName)
+		}
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"event": "failed_enrichment",
+				"error": err,
+				"repo":  e.Repository(),
+				"tag":   e.Release.TagName,
+			}).Warn("couldn't build release changelog")
+		}
+	case *webhookmodels.ProjectCardEventPayload:
+		enrichProjectCard(cfg.BaseURL(), e, logger)
+	case *webhookmodels.ProjectsV2ItemEventPayload:
+		if len(e.Item.ContentNodeID) < 1 || len(e.ToString()) < 1 {
+			return
+		}
+
+		token, err := apiToken()
+		if err == nil {
+			e.Content, err = itemContent(cfg.BaseURL(), token, &e.Item)
+		}
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"event": "failed_enrichment",
+				"error": err,
+				"repo":  e.Repository(),
+				"item":  e.Item.NodeID,
+			}).Warn("couldn't look up project item content")
+		}
+	}
+}
+
+// enrichProjectCard names the card's columns and looks up its issue or pull
+// request.  Each lookup that fails is left out.
+func enrichProjectCard(baseURL string, event *webhookmodels.ProjectCardEventPayload, logger *logrus.Logger) {
+	token, err := apiToken()
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"event": "failed_enrichment",
+			"error": err,
+			"repo":  event.Repository(),
+		}).Warn("couldn't get an api token")
+		return
+	}
+
+	failed := func(lookup string, err error) {
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"event":  "failed_enrichment",
+				"error":  err,
+				"repo":   event.Repository(),
+				"card":   event.Card.ID,
+				"lookup": lookup,
+			}).Warn("couldn't look up project card details")
+		}
+	}
+
+	if event.Card.ColumnID > 0 {
+		event.Column, err = columnName(baseURL, token, event.Card.ColumnID)
+		failed("column", err)
+	}
+	if from := event.MovedFrom(); from > 0 {
+		event.PreviousColumn, err = columnName(baseURL, token, from)
+		failed("previous column", err)
+	}
+	if len(event.Card.ContentURL) > 0 {
+		event.Content, err = cardContent(baseURL, event.Card.ContentURL, token)
+		failed("content", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -16,6 +17,113 @@ var curConfig *Config
 type Watcher struct {
 	Repo    string `yaml:"repo"`
 	Webhook string `yaml:"webhook"`
+	// Secrets are the webhook secrets configured on the repo's hook. More
+	// than one can be active while a secret is being rotated.
+	Secrets []string `yaml:"secrets"`
+	// AllowSHA1 lets a request through on its X-Hub-Signature when it
+	// didn't send an X-Hub-Signature-256.
+	AllowSHA1 bool `yaml:"allow_sha1"`
+	// CI decides which status and check results are announced.
+	CI CIPolicy `yaml:"ci"`
+	// Workflows decides which GitHub Actions runs and jobs are announced.
+	Workflows WorkflowPolicy `yaml:"workflows"`
+	// ProtectedRefs are branch and tag patterns, like main or release/*,
+	// that raise an alert when they're force-pushed or deleted.
+	ProtectedRefs []string `yaml:"protected_refs"`
+	// PullRequestActions are the pull request actions to announce.  Empty
+	// announces DefaultPullRequestActions.
+	PullRequestActions []string `yaml:"pull_request_actions"`
+	// Channels are named Slack webhooks that some events are routed to
+	// instead of Webhook.
+	Channels map[string]string `yaml:"channels"`
+	// Discussions decides where discussion events are announced.
+	Discussions DiscussionPolicy `yaml:"discussions"`
+	// Security decides where and which security alerts are announced.
+	Security SecurityPolicy `yaml:"security"`
+}
+
+// DefaultPullRequestActions are announced for watchers that don't list
+// their own.
+var DefaultPullRequestActions = []string{"opened", "edited", "labeled", "closed"}
+
+// AllowsPullRequestAction returns true if the watcher announces the pull
+// request action.
+func (w *Watcher) AllowsPullRequestAction(action string) bool {
+	actions := w.PullRequestActions
+	if len(actions) < 1 {
+		actions = DefaultPullRequestActions
+	}
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Protects returns true if the branch or tag matches one of the watcher's
+// protected patterns.  Full refs like refs/heads/main are accepted.
+func (w *Watcher) Protects(ref string) bool {
+	ref = strings.TrimPrefix(strings.TrimPrefix(ref, "refs/heads/"), "refs/tags/")
+	for _, pattern := range w.ProtectedRefs {
+		if matched, err := path.Match(pattern, ref); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// DiscussionChannel returns the channel the discussion category is routed
+// to, or an empty string for the watcher's webhook.
+func (w *Watcher) DiscussionChannel(category string) string {
+	for c, channel := range w.Discussions.Categories {
+		if strings.ToLower(c) == strings.ToLower(category) {
+			return channel
+		}
+	}
+	return ""
+}
+
+// CIPolicy filters the CI results announced for a watcher.  The zero value
+// announces every passing and failing result.
+type CIPolicy struct {
+	// FailuresOnly drops passing results, except recoveries
+	FailuresOnly bool `yaml:"failures_only"`
+	// DefaultBranchOnly drops results for commits that aren't on the
+	// repo's default branch
+	DefaultBranchOnly bool `yaml:"default_branch_only"`
+	// NotifyRecovery calls out the pull request author when a check that
+	// failed on their branch passes again
+	NotifyRecovery bool `yaml:"notify_recovery"`
+}
+
+// WorkflowPolicy filters the GitHub Actions runs and jobs announced for a
+// watcher.  Empty lists match everything.
+type WorkflowPolicy struct {
+	// Names are the workflows to announce
+	Names []string `yaml:"names"`
+	// Conclusions are the outcomes to announce, e.g. failure or cancelled
+	Conclusions []string `yaml:"conclusions"`
+	// SlowMinutes announces anything that took longer, whatever its
+	// conclusion.  0 turns it off.
+	SlowMinutes int `yaml:"slow_minutes"`
+}
+
+// DiscussionPolicy routes discussion events for a watcher.
+type DiscussionPolicy struct {
+	// Categories maps discussion category names, like Q&A, to one of the
+	// watcher's Channels
+	Categories map[string]string `yaml:"categories"`
+}
+
+// SecurityPolicy routes and filters dependabot, code scanning and secret
+// scanning alerts for a watcher.
+type SecurityPolicy struct {
+	// Channel is one of the watcher's Channels; empty uses the webhook
+	Channel string `yaml:"channel"`
+	// MinSeverity drops alerts less severe than low, medium, high or
+	// critical.  Empty announces every alert.
+	MinSeverity string `yaml:"min_severity"`
 }
 
 type Watchers []Watcher
@@ -54,6 +162,25 @@ type Config struct {
 	Watchers        Watchers `yaml:"watchers"`
 	MakeTestCalls   bool     `yaml:"test_calls"`
 	Automerge       bool     `yaml:"automerge"`
+	Workers         int      `yaml:"workers"`
+	QueueSize       int      `yaml:"queue_size"`
+	StorePath       string   `yaml:"store_path"`
+	AdminToken      string   `yaml:"admin_token"`
+	RetryAttempts   int      `yaml:"retry_attempts"`
+	RetryBaseMillis int      `yaml:"retry_base_ms"`
+	RetryMaxMillis  int      `yaml:"retry_max_ms"`
+	DeliveryTTLHrs  int      `yaml:"delivery_ttl_hours"`
+	CommitLimit     int      `yaml:"push_commit_limit"`
+	ThreadSecs      int      `yaml:"review_thread_seconds"`
+	// AuditWebhook is the Slack webhook repository administration events
+	// are announced in, for every watcher.  Empty uses each repo's webhook.
+	AuditWebhook string `yaml:"audit_webhook"`
+	// AppID, AppKeyPath and AppInstallationID let the app authenticate as
+	// a GitHub App instead of with APIToken.  The installation ID can be
+	// left at 0 to pick it up from incoming webhooks.
+	AppID             int64  `yaml:"github_app_id"`
+	AppKeyPath        string `yaml:"github_app_key_path"`
+	AppInstallationID int64  `yaml:"github_app_installation_id"`
 }
 
 func (c *Config) BaseURL() string {
@@ -106,6 +233,16 @@ func loadAppConfig() *Config {
 		dev.APIToken = envToken
 	}
 
+	appKeyPath := os.Getenv("GITHUB_APP_KEY_PATH")
+	if len(appKeyPath) > 0 {
+		dev.AppKeyPath = appKeyPath
+	}
+
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	if len(adminToken) > 0 {
+		dev.AdminToken = adminToken
+	}
+
 	automerge := os.Getenv("AUTO_MERGE")
 	if automerge == "true" {
 		dev.Automerge = true
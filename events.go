@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mike-webster/repo-watcher/archive"
+)
+
+// handlerListEvents searches the archive.  Every filter is optional:
+// repo, event, action, sender, since and until (RFC3339) and limit.
+func handlerListEvents(ctx *gin.Context) {
+	deps := ctx.MustGet("deps").(*AppDependencies)
+	q, err := archiveQuery(ctx)
+	if err != nil {
+		ctx.JSON(CodeInvalid, fmt.Sprintf("{\"%v\":\"%v\"}", "reason", err.Error()))
+		return
+	}
+
+	deliveries, err := deps.archive.Find(*q)
+	if err != nil {
+		deps.logger.WithField("error", err).Error("couldn't search archive")
+		ctx.Status(CodeError)
+		return
+	}
+
+	ctx.JSON(CodeOK, deliveries)
+}
+
+func handlerGetEvent(ctx *gin.Context) {
+	deps := ctx.MustGet("deps").(*AppDependencies)
+	d, err := deps.archive.Get(ctx.Param("delivery"))
+	if err != nil {
+		deps.logger.WithField("error", err).Error("couldn't retrieve delivery")
+		ctx.Status(CodeError)
+		return
+	}
+	if d == nil {
+		ctx.JSON(CodeNotFound, fmt.Sprintf("{\"%v\":\"%v\"}", "reason", errNotFound))
+		return
+	}
+
+	ctx.JSON(CodeOK, d)
+}
+
+func archiveQuery(ctx *gin.Context) (*archive.Query, error) {
+	q := &archive.Query{
+		Repo:   ctx.Query("repo"),
+		Event:  ctx.Query("event"),
+		Action: ctx.Query("action"),
+		Sender: ctx.Query("sender"),
+	}
+
+	var err error
+	if since := ctx.Query("since"); len(since) > 0 {
+		q.Since, err = time.Parse(time.RFC3339, since)
+		if err != nil {
+			return nil, fmt.Errorf("invalid since: %v", since)
+		}
+	}
+	if until := ctx.Query("until"); len(until) > 0 {
+		q.Until, err = time.Parse(time.RFC3339, until)
+		if err != nil {
+			return nil, fmt.Errorf("invalid until: %v", until)
+		}
+	}
+	if limit := ctx.Query("limit"); len(limit) > 0 {
+		q.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid limit: %v", limit)
+		}
+	}
+
+	return q, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	env "github.com/mike-webster/repo-watcher/env"
+	webhookmodels "github.com/mike-webster/repo-watcher/webhookmodels"
+)
+
+type gtRequestHeader struct {
+	Event     string `header:"X-Gitea-Event" binding:"required"`
+	Signature string `header:"X-Gitea-Signature" binding:"required"`
+	Delivery  string `header:"X-Gitea-Delivery"`
+}
+
+// giteaProvider receives webhooks from Gitea and Forgejo, which send the
+// same headers and payloads.
+type giteaProvider struct{}
+
+// giteaEvents maps X-Gitea-Event names to their payloads.
+var giteaEvents = map[string]func() webhookmodels.Event{
+	"push":          func() webhookmodels.Event { return &webhookmodels.GiteaPushEventPayload{} },
+	"pull_request":  func() webhookmodels.Event { return &webhookmodels.GiteaPullRequestEventPayload{} },
+	"issues":        func() webhookmodels.Event { return &webhookmodels.GiteaIssuesEventPayload{} },
+	"issue_comment": func() webhookmodels.Event { return &webhookmodels.GiteaIssueCommentEventPayload{} },
+	"release":       func() webhookmodels.Event { return &webhookmodels.GiteaReleaseEventPayload{} },
+}
+
+func (gtp *giteaProvider) Name() string {
+	return providerGitea
+}
+
+func (gtp *giteaProvider) Headers(ctx *gin.Context) (string, string, error) {
+	hdr := &gtRequestHeader{}
+	err := ctx.BindHeader(hdr)
+	if err != nil {
+		return "", "", err
+	}
+
+	return hdr.Event, hdr.Delivery, nil
+}
+
+// Repo pulls the repository name out of a raw webhook body so the matching
+// watcher can be found before the payload is trusted.
+func (gtp *giteaProvider) Repo(body []byte) string {
+	sBody := struct {
+		Repository struct {
+			Name string `json:"name"`
+		} `json:"repository"`
+	}{}
+
+	err := json.Unmarshal(body, &sBody)
+	if err != nil {
+		return ""
+	}
+
+	return sBody.Repository.Name
+}
+
+// Verify checks the body signature, a bare hex sha256 hmac.
+func (gtp *giteaProvider) Verify(ctx *gin.Context, w *env.Watcher, body []byte) error {
+	return verifySignature(w, body, ctx.GetHeader("X-Gitea-Signature"), "")
+}
+
+func (gtp *giteaProvider) Payload(eventName string) (webhookmodels.Event, bool) {
+	newEvent, ok := giteaEvents[eventName]
+	if !ok {
+		return nil, false
+	}
+
+	return newEvent(), true
+}
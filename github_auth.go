@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"sync"
+
+	env "github.com/mike-webster/repo-watcher/env"
+	"github.com/mike-webster/repo-watcher/githubapp"
+)
+
+var (
+	ghApp     *githubapp.App
+	ghAppErr  error
+	ghAppOnce sync.Once
+)
+
+// githubApp returns the configured GitHub App, or nil when the app still
+// authenticates with a personal access token.
+func githubApp() (*githubapp.App, error) {
+	ghAppOnce.Do(func() {
+		cfg := env.GetConfig()
+		if cfg.AppID < 1 {
+			return
+		}
+
+		ghApp, ghAppErr = githubapp.New(cfg.AppID, cfg.AppKeyPath, cfg.AppInstallationID, cfg.BaseURL())
+	})
+
+	return ghApp, ghAppErr
+}
+
+// apiToken returns the token every GHE API call should be made with: an
+// installation token when running as a GitHub App, otherwise the configured
+// personal access token.
+func apiToken() (string, error) {
+	app, err := githubApp()
+	if err != nil {
+		return "", err
+	}
+	if app == nil {
+		return env.GetConfig().APIToken, nil
+	}
+
+	return app.Token()
+}
+
+// rememberInstallation picks the app installation out of a verified github
+// delivery, for when the installation ID isn't configured.
+func rememberInstallation(body []byte) {
+	app, err := githubApp()
+	if err != nil || app == nil {
+		return
+	}
+
+	sBody := struct {
+		Installation struct {
+			ID int64 `json:"id"`
+		} `json:"installation"`
+	}{}
+	err = json.Unmarshal(body, &sBody)
+	if err != nil || sBody.Installation.ID < 1 {
+		return
+	}
+
+	app.SetInstallation(sBody.Installation.ID)
+}
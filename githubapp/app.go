@@ -0,0 +1,209 @@
+package githubapp
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jwtLifetime is kept under github's ten minute limit.
+const jwtLifetime = 9 * time.Minute
+
+// clockSkew backdates the JWT in case our clock is ahead of github's.
+const clockSkew = time.Minute
+
+// refreshWindow is how long before an installation token expires that a new
+// one is requested.
+const refreshWindow = 5 * time.Minute
+
+var errNoInstallation = errors.New("no installation ID configured or seen in a webhook yet")
+
+// App authenticates API calls as an installation of a GitHub App.
+// Installation tokens are cached and refreshed shortly before they expire.
+type App struct {
+	ID      int64
+	Key     *rsa.PrivateKey
+	BaseURL string
+	Client  *http.Client
+
+	mu             sync.Mutex
+	installationID int64
+	token          string
+	expiresAt      time.Time
+	now            func() time.Time
+}
+
+// New builds an app from its ID and the path to its private key.  When
+// installationID is 0 it's picked up from the first webhook that has one.
+func New(id int64, keyPath string, installationID int64, baseURL string) (*App, error) {
+	key, err := LoadKey(keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &App{
+		ID:             id,
+		Key:            key,
+		BaseURL:        strings.TrimSuffix(baseURL, "/"),
+		installationID: installationID,
+	}, nil
+}
+
+// LoadKey reads the PEM encoded private key downloaded from the app's
+// settings page.
+func LoadKey(path string) (*rsa.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in %v", path)
+	}
+
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%v isn't an RSA key", path)
+	}
+
+	return key, nil
+}
+
+// SetInstallation remembers the installation a webhook came from.  An
+// installation ID that was configured up front is never replaced.
+func (a *App) SetInstallation(id int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.installationID == 0 {
+		a.installationID = id
+	}
+}
+
+// Installation returns the installation the app authenticates as.
+func (a *App) Installation() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.installationID
+}
+
+// JWT returns a signed token identifying the app itself.
+func (a *App) JWT() (string, error) {
+	now := a.clock()
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-clockSkew).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": a.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := fmt.Sprint(
+		base64.RawURLEncoding.EncodeToString(header), ".",
+		base64.RawURLEncoding.EncodeToString(claims),
+	)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, a.Key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprint(unsigned, ".", base64.RawURLEncoding.EncodeToString(sig)), nil
+}
+
+// Token returns an installation token, requesting a new one when the cached
+// one is missing or about to expire.
+func (a *App) Token() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.installationID == 0 {
+		return "", errNoInstallation
+	}
+	if len(a.token) > 0 && a.clock().Add(refreshWindow).Before(a.expiresAt) {
+		return a.token, nil
+	}
+
+	token, expiresAt, err := a.requestToken()
+	if err != nil {
+		return "", err
+	}
+	a.token, a.expiresAt = token, expiresAt
+
+	return token, nil
+}
+
+func (a *App) requestToken() (string, time.Time, error) {
+	jwt, err := a.JWT()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", a.BaseURL, a.installationID)
+	req, err := http.NewRequest("POST", url, nil)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Add("Authorization", fmt.Sprint("Bearer ", jwt))
+	req.Header.Add("Accept", "application/vnd.github+json")
+
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", time.Time{}, errors.New(fmt.Sprint("installation token request failed: ", resp.StatusCode))
+	}
+
+	var body struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return body.Token, body.ExpiresAt, nil
+}
+
+func (a *App) clock() time.Time {
+	if a.now != nil {
+		return a.now()
+	}
+	return time.Now()
+}
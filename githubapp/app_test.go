@@ -0,0 +1,100 @@
+package githubapp
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestApp(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("LoadKey", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "githubapp")
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, "app.pem")
+		data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+		if err := ioutil.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		loaded, err := LoadKey(path)
+		assert.Equal(t, nil, err)
+		assert.T(t, key.Equal(loaded))
+	})
+
+	t.Run("JWT", func(t *testing.T) {
+		now := time.Unix(1600000000, 0)
+		a := &App{ID: 42, Key: key, now: func() time.Time { return now }}
+		jwt, err := a.JWT()
+		assert.Equal(t, nil, err)
+
+		parts := strings.Split(jwt, ".")
+		assert.Equal(t, 3, len(parts))
+
+		sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		assert.Equal(t, nil, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, sum[:], sig))
+
+		raw, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		claims := map[string]int64{}
+		assert.Equal(t, nil, json.Unmarshal(raw, &claims))
+		assert.Equal(t, int64(42), claims["iss"])
+		assert.Equal(t, now.Add(-clockSkew).Unix(), claims["iat"])
+		assert.Equal(t, now.Add(jwtLifetime).Unix(), claims["exp"])
+	})
+
+	t.Run("CachesAndRefreshesToken", func(t *testing.T) {
+		now := time.Now()
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			assert.Equal(t, "/app/installations/7/access_tokens", r.URL.Path)
+			assert.T(t, strings.HasPrefix(r.Header.Get("Authorization"), "Bearer "))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"token":"token-%d","expires_at":"%s"}`, calls, now.Add(time.Hour).Format(time.RFC3339))
+		}))
+		defer server.Close()
+
+		a := &App{ID: 42, Key: key, BaseURL: server.URL, now: func() time.Time { return now }}
+		_, err := a.Token()
+		assert.Equal(t, errNoInstallation, err)
+
+		a.SetInstallation(7)
+		a.SetInstallation(8)
+		assert.Equal(t, int64(7), a.Installation())
+
+		token, err := a.Token()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "token-1", token)
+
+		token, _ = a.Token()
+		assert.Equal(t, "token-1", token)
+		assert.Equal(t, 1, calls)
+
+		now = now.Add(56 * time.Minute)
+		token, _ = a.Token()
+		assert.Equal(t, "token-2", token)
+		assert.Equal(t, 2, calls)
+	})
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	env "github.com/mike-webster/repo-watcher/env"
+	webhookmodels "github.com/mike-webster/repo-watcher/webhookmodels"
+)
+
+type glRequestHeader struct {
+	Event    string `header:"X-Gitlab-Event" binding:"required"`
+	Token    string `header:"X-Gitlab-Token" binding:"required"`
+	Delivery string `header:"X-Gitlab-Event-UUID"`
+}
+
+// gitLabProvider receives webhooks from a self-hosted GitLab.
+type gitLabProvider struct{}
+
+// gitLabEvents maps X-Gitlab-Event names to their payloads.
+var gitLabEvents = map[string]func() webhookmodels.Event{
+	"Push Hook":          func() webhookmodels.Event { return &webhookmodels.GitLabPushEventPayload{} },
+	"Tag Push Hook":      func() webhookmodels.Event { return &webhookmodels.GitLabPushEventPayload{} },
+	"Merge Request Hook": func() webhookmodels.Event { return &webhookmodels.GitLabMergeRequestEventPayload{} },
+	"Note Hook":          func() webhookmodels.Event { return &webhookmodels.GitLabNoteEventPayload{} },
+	"Issue Hook":         func() webhookmodels.Event { return &webhookmodels.GitLabIssueEventPayload{} },
+	"Pipeline Hook":      func() webhookmodels.Event { return &webhookmodels.GitLabPipelineEventPayload{} },
+}
+
+func (glp *gitLabProvider) Name() string {
+	return providerGitLab
+}
+
+func (glp *gitLabProvider) Headers(ctx *gin.Context) (string, string, error) {
+	hdr := &glRequestHeader{}
+	err := ctx.BindHeader(hdr)
+	if err != nil {
+		return "", "", err
+	}
+
+	return hdr.Event, hdr.Delivery, nil
+}
+
+// Repo pulls the project name out of a raw webhook body so the matching
+// watcher can be found before the payload is trusted.
+func (glp *gitLabProvider) Repo(body []byte) string {
+	sBody := struct {
+		Project struct {
+			Name string `json:"name"`
+		} `json:"project"`
+	}{}
+
+	err := json.Unmarshal(body, &sBody)
+	if err != nil {
+		return ""
+	}
+
+	return sBody.Project.Name
+}
+
+// Verify checks the X-Gitlab-Token; gitlab doesn't sign the body.
+func (glp *gitLabProvider) Verify(ctx *gin.Context, w *env.Watcher, body []byte) error {
+	return verifyToken(w, ctx.GetHeader("X-Gitlab-Token"))
+}
+
+func (glp *gitLabProvider) Payload(eventName string) (webhookmodels.Event, bool) {
+	newEvent, ok := gitLabEvents[eventName]
+	if !ok {
+		return nil, false
+	}
+
+	return newEvent(), true
+}
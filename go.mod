@@ -7,5 +7,6 @@ require (
 	github.com/gin-gonic/gin v1.6.3
 	github.com/mike-webster/teamcity10 v0.0.1
 	github.com/sirupsen/logrus v1.6.0
+	go.etcd.io/bbolt v1.3.5
 	gopkg.in/yaml.v1 v1.0.0-20140924161607-9f9df34309c0
 )
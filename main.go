@@ -3,20 +3,44 @@ package main
 import (
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
+	"os"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/mike-webster/repo-watcher/archive"
 	dispatchers "github.com/mike-webster/repo-watcher/dispatchers"
 	env "github.com/mike-webster/repo-watcher/env"
 	models "github.com/mike-webster/repo-watcher/models"
+	"github.com/mike-webster/repo-watcher/storage"
 	"github.com/sirupsen/logrus"
 )
 
+var (
+	replayFlag  = flag.Bool("replay", false, "replay archived deliveries instead of starting the app")
+	replayIDs   = flag.String("deliveries", "", "comma separated delivery IDs to replay")
+	replayRepo  = flag.String("repo", "", "replay every archived delivery for this repo")
+	replaySince = flag.String("since", "", "replay deliveries received after this time (RFC3339)")
+	replayUntil = flag.String("until", "", "replay deliveries received before this time (RFC3339)")
+	replayTo    = flag.String("channel", "", "send replayed messages to this slack webhook instead")
+	replayDry   = flag.Bool("dry-run", false, "only print the replayed messages")
+)
+
 func main() {
+	flag.Parse()
 	cfg, logger := initApp()
 
+	if *replayFlag {
+		err := runReplay(cfg, logger)
+		if err != nil {
+			logger.WithField("error", err).Error("replay failed")
+			os.Exit(1)
+		}
+		return
+	}
+
 	if cfg.RunType == "solo" {
 		deps := AppDependencies{
 			dispatchers: getLocalDispatchers(),
@@ -32,13 +56,35 @@ func main() {
 		}
 	} else if cfg.RunType == "api" {
 		logger.WithField("run_type", "api").Info()
+		store, err := storage.Open(cfg.StorePath)
+		if err != nil {
+			panic(err)
+		}
+		defer store.Close()
+
+		deadLetters := &dispatchers.DeadLetterStore{DB: store}
 		deps := AppDependencies{
-			dispatchers: getSlackDispatchers(),
+			dispatchers: getSlackDispatchers(deadLetters),
 			logger:      logger,
+			store:       store,
+			deadLetters: deadLetters,
+			deliveries:  newDeliveryStore(store, time.Duration(cfg.DeliveryTTLHrs)*time.Hour),
+			archive:     &archive.Store{DB: store},
+			ciStates:    newCIStateStore(store),
+			deployments: newDeploymentStore(store),
+			reviews:     newReviewStore(store),
 		}
+		go pruneStores(map[string]pruner{
+			deliveryBucket:   deps.deliveries,
+			ciStateBucket:    deps.ciStates,
+			deploymentBucket: deps.deployments,
+			reviewBucket:     deps.reviews,
+		}, logger)
+		deps.queue = newWorkerPool(cfg.Workers, cfg.QueueSize, time.Duration(cfg.ThreadSecs)*time.Second, &deps)
+		deps.queue.Start()
 
 		router := SetupServer(fmt.Sprint(cfg.Port), &deps)
-		err := router.Run()
+		err = router.Run()
 		if err != nil {
 			panic(err)
 		}
@@ -47,6 +93,64 @@ func main() {
 	}
 }
 
+// runReplay is the command line version of POST /v1/replay.  The store can
+// only be opened by one process, so this is for when the api isn't running.
+func runReplay(cfg *env.Config, logger *logrus.Logger) error {
+	store, err := storage.Open(cfg.StorePath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	deadLetters := &dispatchers.DeadLetterStore{DB: store}
+	deps := AppDependencies{
+		dispatchers: getSlackDispatchers(deadLetters),
+		logger:      logger,
+		store:       store,
+		deadLetters: deadLetters,
+		archive:     &archive.Store{DB: store},
+	}
+
+	req := replayRequest{
+		Repo:    *replayRepo,
+		Mode:    replayDispatch,
+		Channel: *replayTo,
+	}
+	if len(*replayIDs) > 0 {
+		req.Deliveries = strings.Split(*replayIDs, ",")
+	}
+	if len(*replaySince) > 0 {
+		req.Since, err = time.Parse(time.RFC3339, *replaySince)
+		if err != nil {
+			return err
+		}
+	}
+	if len(*replayUntil) > 0 {
+		req.Until, err = time.Parse(time.RFC3339, *replayUntil)
+		if err != nil {
+			return err
+		}
+	}
+	if len(req.Channel) > 0 {
+		req.Mode = replayChannel
+	}
+	if *replayDry {
+		req.Mode = replayDryRun
+	}
+
+	results, err := replay(&deps, &req, logger)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
 func initApp() (*env.Config, *logrus.Logger) {
 	cfg := env.GetConfig()
 	logger := defaultLogger(nil)
@@ -73,7 +177,12 @@ func runCheck(deps *AppDependencies, logger *logrus.Logger) {
 	}).Debug("previous ids")
 
 	url := fmt.Sprint(cfg.BaseURL(), fmt.Sprintf(cfg.EventEndpoint, cfg.OrgName, cfg.RepoToWatch))
-	eventsBody, err := MakeRequest(url, "", cfg.APIToken)
+	token, err := apiToken()
+	if err != nil {
+		logger.WithField("error", err).Error("couldn't get an api token")
+		return
+	}
+	eventsBody, err := MakeRequest(url, "", token)
 	if err != nil {
 		logger.WithField("error", err).Error("request for events failed")
 		return
@@ -131,6 +240,35 @@ func runCheck(deps *AppDependencies, logger *logrus.Logger) {
 	}
 }
 
+// pruner is a store that expires its own records.
+type pruner interface {
+	Prune() (int, error)
+}
+
+// pruneStores clears out expired records once an hour so the store doesn't
+// grow forever.
+func pruneStores(stores map[string]pruner, logger *logrus.Logger) {
+	for {
+		for name, s := range stores {
+			removed, err := s.Prune()
+			if err != nil {
+				logger.WithFields(logrus.Fields{
+					"error": err,
+					"store": name,
+				}).Error("couldn't prune store")
+				continue
+			}
+
+			logger.WithFields(logrus.Fields{
+				"event":   "pruned_store",
+				"store":   name,
+				"removed": removed,
+			}).Debug()
+		}
+		time.Sleep(time.Hour)
+	}
+}
+
 func announceEvent(e models.RepositoryEvent, deps *AppDependencies, logger *logrus.Logger) {
 	message := e.Say()
 	if strings.Contains(message, "#{actor}") {
@@ -158,7 +296,11 @@ func announceEvent(e models.RepositoryEvent, deps *AppDependencies, logger *logr
 
 func getNameFromUsername(username string) (string, error) {
 	cfg := env.GetConfig()
-	userBody, err := MakeRequest(fmt.Sprint(cfg.BaseURL(), cfg.UserEndpoint), username, cfg.APIToken)
+	token, err := apiToken()
+	if err != nil {
+		return "", err
+	}
+	userBody, err := MakeRequest(fmt.Sprint(cfg.BaseURL(), cfg.UserEndpoint), username, token)
 	if err != nil {
 		return "", err
 	}
@@ -191,12 +333,44 @@ func camelRegexp(str string) string {
 	return str
 }
 
-func getSlackDispatchers() dispatchers.Dispatchers {
+func getSlackDispatchers(deadLetters *dispatchers.DeadLetterStore) dispatchers.Dispatchers {
+	cfg := env.GetConfig()
 	var ds dispatchers.Dispatchers
-	for _, d := range env.GetConfig().Watchers {
-		ds = append(ds, &dispatchers.SlackDispatcher{
-			URL:      d.Webhook,
-			RepoName: d.Repo,
+	for _, d := range cfg.Watchers {
+		ds = append(ds, &dispatchers.RetryDispatcher{
+			Dispatcher: &dispatchers.SlackDispatcher{
+				URL:      d.Webhook,
+				RepoName: d.Repo,
+			},
+			MaxAttempts: cfg.RetryAttempts,
+			BaseDelay:   time.Duration(cfg.RetryBaseMillis) * time.Millisecond,
+			MaxDelay:    time.Duration(cfg.RetryMaxMillis) * time.Millisecond,
+			DeadLetters: deadLetters,
+		})
+		for name, url := range d.Channels {
+			ds = append(ds, &dispatchers.RetryDispatcher{
+				Dispatcher: &dispatchers.SlackDispatcher{
+					URL:         url,
+					RepoName:    d.Repo,
+					ChannelName: name,
+				},
+				MaxAttempts: cfg.RetryAttempts,
+				BaseDelay:   time.Duration(cfg.RetryBaseMillis) * time.Millisecond,
+				MaxDelay:    time.Duration(cfg.RetryMaxMillis) * time.Millisecond,
+				DeadLetters: deadLetters,
+			})
+		}
+	}
+	if len(cfg.AuditWebhook) > 0 {
+		ds = append(ds, &dispatchers.RetryDispatcher{
+			Dispatcher: &dispatchers.SlackDispatcher{
+				URL:      cfg.AuditWebhook,
+				RepoName: auditRepo,
+			},
+			MaxAttempts: cfg.RetryAttempts,
+			BaseDelay:   time.Duration(cfg.RetryBaseMillis) * time.Millisecond,
+			MaxDelay:    time.Duration(cfg.RetryMaxMillis) * time.Millisecond,
+			DeadLetters: deadLetters,
 		})
 	}
 	return ds
@@ -44,3 +44,27 @@ func TestMarkdownHelpers(t *testing.T) {
 		assert.Equal(t, expected, MarkdownList([]string{"Testing1", "Testing2", "Testing3"}))
 	})
 }
+
+func TestToSlack(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Input    string
+		Expected string
+	}{
+		{Name: "Heading", Input: "## What's new", Expected: "*What's new*"},
+		{Name: "Bold", Input: "this is **really** __bold__", Expected: "this is *really* *bold*"},
+		{Name: "Italic", Input: "this is *slanted*", Expected: "this is _slanted_"},
+		{Name: "Strike", Input: "~~gone~~", Expected: "~gone~"},
+		{Name: "Link", Input: "see [the docs](https://example.com/docs)", Expected: "see <https://example.com/docs|the docs>"},
+		{Name: "Image", Input: "![logo](https://example.com/logo.png)", Expected: "<https://example.com/logo.png|logo>"},
+		{Name: "Bullets", Input: "* one\n- two\n  + three", Expected: "• one\n• two\n  • three"},
+		{Name: "InlineCode", Input: "run `**not bold**` now", Expected: "run `**not bold**` now"},
+		{Name: "CodeBlock", Input: "```\n# not a heading\n```", Expected: "```\n# not a heading\n```"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			assert.Equal(t, c.Expected, ToSlack(c.Input))
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	reImage   = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)[^)]*\)`)
+	reLink    = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)[^)]*\)`)
+	reBold    = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	reItalic  = regexp.MustCompile(`(^|[^\w*])\*([^*\s](?:[^*]*[^*\s])?)\*`)
+	reStrike  = regexp.MustCompile(`~~(.+?)~~`)
+	reHeading = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*#*$`)
+	reBullet  = regexp.MustCompile(`^(\s*)[*+-]\s+`)
+)
+
+// boldMarker stands in for slack's bold asterisks until italics have been
+// converted, so the two aren't confused.
+const boldMarker = "\x00"
+
+// ToSlack converts GitHub flavored markdown into slack's mrkdwn.  Code
+// blocks and inline code are left alone.
+func ToSlack(md string) string {
+	lines := strings.Split(strings.Replace(md, "\r\n", "\n", -1), "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		lines[i] = slackLine(line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func slackLine(line string) string {
+	heading := false
+	if m := reHeading.FindStringSubmatch(line); m != nil {
+		line, heading = m[1], true
+	}
+	line = reBullet.ReplaceAllString(line, "$1• ")
+
+	// odd segments are inline code
+	segments := strings.Split(line, "`")
+	for i := 0; i < len(segments); i += 2 {
+		s := segments[i]
+		s = reImage.ReplaceAllString(s, "<$2|$1>")
+		s = reLink.ReplaceAllString(s, "<$2|$1>")
+		s = reBold.ReplaceAllString(s, boldMarker+"$1$2"+boldMarker)
+		s = reItalic.ReplaceAllString(s, "${1}_${2}_")
+		s = reStrike.ReplaceAllString(s, "~$1~")
+		segments[i] = strings.Replace(s, boldMarker, "*", -1)
+	}
+	line = strings.Join(segments, "`")
+
+	if heading {
+		return MarkdownBold(strings.Trim(line, "*"))
+	}
+	return line
+}
@@ -2,7 +2,8 @@ package main
 
 import (
 	"bytes"
-	"encoding/json"
+	"crypto/subtle"
+	"fmt"
 	"io/ioutil"
 	"runtime/debug"
 	"strings"
@@ -45,15 +46,29 @@ func setDependencies(deps *AppDependencies) gin.HandlerFunc {
 	}
 }
 
-func requestLogger() gin.HandlerFunc {
+// requireAdmin only lets requests through that carry the configured admin
+// token.  With no token configured the admin routes are closed.
+func requireAdmin() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		defer func(ctx *gin.Context) logrus.FieldLogger {
-			if ctx.Request.URL.Path == "/" && ctx.Writer.Status() == 200 {
-				return nil
-			}
+		token := env.GetConfig().AdminToken
+		given := ctx.GetHeader("X-Admin-Token")
+		if len(token) < 1 || subtle.ConstantTimeCompare([]byte(token), []byte(given)) != 1 {
+			defaultLogger(ctx).WithFields(logrus.Fields{
+				"event": "unauthorized_admin_request",
+				"path":  ctx.Request.URL.Path,
+			}).Warn("rejected admin request")
+			ctx.AbortWithStatusJSON(CodeUnauth, fmt.Sprintf("{\"%v\":\"%v\"}", "reason", errInvalidSecret))
+			return
+		}
+		ctx.Next()
+	}
+}
 
-			// log body if one is given]
-			strBody := ""
+func requestLogger() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// log body if one is given
+		strBody := ""
+		if ctx.Request.Body != nil {
 			body, err := ioutil.ReadAll(ctx.Request.Body)
 			if err != nil {
 				defaultLogger(ctx).WithField("error", err).Error("cant read request body")
@@ -65,43 +80,36 @@ func requestLogger() gin.HandlerFunc {
 				strBody = strings.Replace(strBody, "\n", "", -1)
 				strBody = strings.Replace(strBody, "\t", "", -1)
 			}
+		}
 
-			logger := defaultLogger(ctx).WithFields(logrus.Fields{
-				"client_ip":    ctx.ClientIP(),
-				"event":        "http.in",
-				"method":       ctx.Request.Method,
-				"path":         ctx.GetString("originalPath"),
-				"query":        ctx.Request.URL.RawQuery,
-				"referer":      ctx.Request.Referer(),
-				"status":       ctx.Writer.Status(),
-				"user_agent":   ctx.Request.UserAgent(),
-				"git_event":    ctx.Request.Header.Get("X-GitHub-Event"),
-				"request_body": strBody,
-			})
-
-			if len(ctx.Errors) > 0 {
-				logger.Error(strings.TrimSpace(ctx.Errors.String()))
-			} else {
-				logger.Info()
-			}
+		ctx.Next()
 
-			sBody := struct {
-				Repository struct {
-					Name string `json:"name"`
-				} `json:"repository"`
-			}{}
+		if ctx.Request.URL.Path == "/" && ctx.Writer.Status() == 200 {
+			return
+		}
 
-			err = json.Unmarshal([]byte(strBody), &sBody)
-			if err != nil {
-				logger.WithField("error", err).Error("couldn't unmarshall body to get repo name")
-			}
+		if ctx.Writer.Status() == CodeUnauth {
+			// don't keep unverified payloads around in the logs
+			strBody = ""
+		}
 
-			ctx.Set("logger", defaultLogger(ctx).WithFields(logrus.Fields{
-				"git_event": ctx.Request.Header.Get("X-GitHub-Event"),
-				"git_repo":  sBody.Repository.Name,
-			}))
+		logger := defaultLogger(ctx).WithFields(logrus.Fields{
+			"client_ip":    ctx.ClientIP(),
+			"event":        "http.in",
+			"method":       ctx.Request.Method,
+			"path":         ctx.GetString("originalPath"),
+			"query":        ctx.Request.URL.RawQuery,
+			"referer":      ctx.Request.Referer(),
+			"status":       ctx.Writer.Status(),
+			"user_agent":   ctx.Request.UserAgent(),
+			"git_event":    ctx.Request.Header.Get("X-GitHub-Event"),
+			"request_body": strBody,
+		})
 
-			return logger
-		}(ctx)
+		if len(ctx.Errors) > 0 {
+			logger.Error(strings.TrimSpace(ctx.Errors.String()))
+		} else {
+			logger.Info()
+		}
 	}
 }
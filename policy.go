@@ -0,0 +1,200 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	env "github.com/mike-webster/repo-watcher/env"
+	webhookmodels "github.com/mike-webster/repo-watcher/webhookmodels"
+	"github.com/sirupsen/logrus"
+)
+
+// allowEvent applies the watcher's policies to the event before it's
+// rendered.  It returns false when the event shouldn't be announced.
+func allowEvent(deps *AppDependencies, event webhookmodels.Event, logger *logrus.Logger) bool {
+	switch e := event.(type) {
+	case *webhookmodels.DeploymentStatusEventPayload:
+		return allowDeploymentStatus(deps, e, logger)
+	case *webhookmodels.PullRequestReviewEventPayload:
+		tallyReview(deps, e, logger)
+	case *webhookmodels.PullRequestEventPayload:
+		if e.Action == "closed" {
+			forgetReviews(deps, e, logger)
+		}
+	}
+
+	w := env.GetConfig().Watchers.Select(event.Repository())
+	if w == nil {
+		return true
+	}
+
+	switch e := event.(type) {
+	case webhookmodels.CIEvent:
+		return allowCI(deps, &w.CI, e, logger)
+	case webhookmodels.WorkflowEvent:
+		return allowWorkflow(&w.Workflows, e)
+	case *webhookmodels.PullRequestEventPayload:
+		return w.AllowsPullRequestAction(e.Action)
+	case webhookmodels.SecurityAlert:
+		return webhookmodels.SeverityAtLeast(e.Severity(), w.Security.MinSeverity)
+	}
+
+	return true
+}
+
+// allowReplay runs the watcher's filters without the state stores, so
+// replaying an event doesn't record CI, deployment or review state again.
+func allowReplay(event webhookmodels.Event, logger *logrus.Logger) bool {
+	return allowEvent(&AppDependencies{logger: logger}, event, logger)
+}
+
+// allowCI filters CI results and marks the ones that are recoveries.
+func allowCI(deps *AppDependencies, policy *env.CIPolicy, event webhookmodels.CIEvent, logger *logrus.Logger) bool {
+	result := event.CIResult()
+	if result.State == webhookmodels.CIPending {
+		return false
+	}
+
+	recovered := false
+	if policy.NotifyRecovery && deps.ciStates != nil {
+		failure, err := deps.ciStates.Record(&result)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"error":   err,
+				"repo":    result.Repo,
+				"context": result.Context,
+			}).Error("couldn't record ci result")
+		}
+		if failure != nil {
+			recovered = true
+			event.SetRecovery(&webhookmodels.CIRecovery{Author: recoveryAuthor(&result, failure, logger)})
+		}
+	}
+
+	if policy.DefaultBranchOnly && len(result.DefaultBranch) > 0 && !result.OnBranch(result.DefaultBranch) {
+		return false
+	}
+	if policy.FailuresOnly && result.State == webhookmodels.CISuccess && !recovered {
+		return false
+	}
+
+	return true
+}
+
+// allowDeploymentStatus only lets a status through when it moves the
+// deployment to a new state, and fills in the states before it.
+func allowDeploymentStatus(deps *AppDependencies, event *webhookmodels.DeploymentStatusEventPayload, logger *logrus.Logger) bool {
+	if deps.deployments == nil {
+		return true
+	}
+
+	previous, changed, err := deps.deployments.Record(event)
+	if err != nil {
+		// better to announce it without the history
+		logger.WithFields(logrus.Fields{
+			"error":      err,
+			"repo":       event.Repository(),
+			"deployment": event.Deployment.ID,
+		}).Error("couldn't record deployment status")
+		return true
+	}
+
+	event.States = previous
+	return changed
+}
+
+// tallyReview records the review and fills in where the pull request's
+// reviews stand.
+func tallyReview(deps *AppDependencies, event *webhookmodels.PullRequestReviewEventPayload, logger *logrus.Logger) {
+	if deps.reviews == nil {
+		return
+	}
+
+	tally, err := deps.reviews.Record(event)
+	if err != nil {
+		// better to announce it without the tally
+		logger.WithFields(logrus.Fields{
+			"error":        err,
+			"repo":         event.Repository(),
+			"pull_request": event.PullRequest.Number,
+		}).Error("couldn't record review")
+		return
+	}
+
+	event.Tally = tally
+}
+
+// forgetReviews drops the reviews of a closed pull request.
+func forgetReviews(deps *AppDependencies, event *webhookmodels.PullRequestEventPayload, logger *logrus.Logger) {
+	if deps.reviews == nil {
+		return
+	}
+
+	if err := deps.reviews.Forget(event.Repo, event.PullRequest.Number); err != nil {
+		logger.WithFields(logrus.Fields{
+			"error":        err,
+			"repo":         event.Repository(),
+			"pull_request": event.PullRequest.Number,
+		}).Error("couldn't forget reviews")
+	}
+}
+
+// allowWorkflow filters GitHub Actions runs and jobs by workflow name and
+// conclusion.  Slow ones get through either way.
+func allowWorkflow(policy *env.WorkflowPolicy, event webhookmodels.WorkflowEvent) bool {
+	result := event.WorkflowResult()
+	if len(policy.Names) > 0 && !containsFold(policy.Names, result.Workflow) {
+		return false
+	}
+
+	slow := time.Duration(policy.SlowMinutes) * time.Minute
+	if policy.SlowMinutes > 0 && result.Duration > slow {
+		return true
+	}
+	if len(policy.Conclusions) > 0 && !containsFold(policy.Conclusions, result.Conclusion) {
+		return false
+	}
+
+	return true
+}
+
+// containsFold returns true if the list has the value, ignoring case.
+func containsFold(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// recoveryAuthor names the author of the pull request that went green, or
+// returns an empty string if they can't be found.
+func recoveryAuthor(result *webhookmodels.CIResult, failure *ciFailure, logger *logrus.Logger) string {
+	prs := result.PullRequests
+	if len(prs) < 1 {
+		prs = failure.PullRequests
+	}
+
+	token, err := apiToken()
+	if err != nil {
+		logger.WithField("error", err).Warn("couldn't get an api token")
+		return ""
+	}
+	login, err := pullRequestAuthor(env.GetConfig().BaseURL(), token, result, prs)
+	if err != nil || len(login) < 1 {
+		logger.WithFields(logrus.Fields{
+			"event": "failed_enrichment",
+			"error": err,
+			"repo":  result.Repo,
+			"sha":   result.SHA,
+		}).Warn("couldn't find the pull request author")
+		return ""
+	}
+
+	name, err := getNameFromUsername(login)
+	if err != nil {
+		return login
+	}
+	return name
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	webhookmodels "github.com/mike-webster/repo-watcher/webhookmodels"
+)
+
+// inertiaPreview is the media type older GHE versions need before they'll
+// answer on the classic projects endpoints.
+const inertiaPreview = "application/vnd.github.inertia-preview+json"
+
+// columnTTL is how long a project column's name is remembered.  Columns are
+// rarely renamed, and busy boards move a lot of cards.
+const columnTTL = time.Hour
+
+type cachedName struct {
+	name    string
+	expires time.Time
+}
+
+// nameCache remembers names looked up on the API for a while.
+type nameCache struct {
+	ttl   time.Duration
+	mu    sync.Mutex
+	names map[string]cachedName
+}
+
+func newNameCache(ttl time.Duration) *nameCache {
+	return &nameCache{ttl: ttl, names: map[string]cachedName{}}
+}
+
+// Get returns the cached name for the key, looking it up and caching it if
+// it isn't cached or has expired.
+func (nc *nameCache) Get(key string, lookup func() (string, error)) (string, error) {
+	nc.mu.Lock()
+	cached, ok := nc.names[key]
+	nc.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.name, nil
+	}
+
+	name, err := lookup()
+	if err != nil {
+		return "", err
+	}
+
+	nc.mu.Lock()
+	nc.names[key] = cachedName{name: name, expires: time.Now().Add(nc.ttl)}
+	nc.mu.Unlock()
+	return name, nil
+}
+
+var columnNames = newNameCache(columnTTL)
+
+type apiColumn struct {
+	Name string `json:"name"`
+}
+
+type apiContent struct {
+	Number      int       `json:"number"`
+	Title       string    `json:"title"`
+	URL         string    `json:"html_url"`
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+// columnName returns the name of the classic project column.
+func columnName(baseURL string, token string, id int64) (string, error) {
+	return columnNames.Get(fmt.Sprint(baseURL, "|", id), func() (string, error) {
+		body, err := projectsRequest(fmt.Sprintf("%s/projects/columns/%d", baseURL, id), token)
+		if err != nil {
+			return "", err
+		}
+		var column apiColumn
+		err = json.Unmarshal(*body, &column)
+		return column.Name, err
+	})
+}
+
+// cardContent looks up the issue or pull request a classic project card's
+// content_url points at.  The URL comes from the payload, so it has to be on
+// the API host before the token is sent to it.
+func cardContent(baseURL string, contentURL string, token string) (*webhookmodels.ProjectContent, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	content, err := url.Parse(contentURL)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(content.Scheme, base.Scheme) || !strings.EqualFold(content.Host, base.Host) {
+		return nil, fmt.Errorf("content url isn't on the api host: %s", contentURL)
+	}
+
+	body, err := projectsRequest(contentURL, token)
+	if err != nil {
+		return nil, err
+	}
+	var found apiContent
+	err = json.Unmarshal(*body, &found)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhookmodels.ProjectContent{
+		Number:      found.Number,
+		Title:       found.Title,
+		URL:         found.URL,
+		PullRequest: found.PullRequest != nil,
+	}, nil
+}
+
+// projectsRequest GETs a classic projects endpoint.
+func projectsRequest(url string, token string) (*[]byte, error) {
+	req, err := getRequest(url, "", token)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Accept", inertiaPreview)
+
+	return doRequest(req)
+}
+
+const itemContentQuery = `query($id: ID!) {
+  node(id: $id) {
+    ... on Issue { number title url }
+    ... on PullRequest { number title url }
+    ... on DraftIssue { title }
+  }
+}`
+
+type graphQLContent struct {
+	Data struct {
+		Node *struct {
+			Number int    `json:"number"`
+			Title  string `json:"title"`
+			URL    string `json:"url"`
+		} `json:"node"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// itemContent looks up the issue, pull request or draft issue behind a
+// projects v2 item.  Item contents are only available through GraphQL.
+func itemContent(baseURL string, token string, item *webhookmodels.ProjectsV2Item) (*webhookmodels.ProjectContent, error) {
+	query, err := json.Marshal(map[string]interface{}{
+		"query":     itemContentQuery,
+		"variables": map[string]string{"id": item.ContentNodeID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", graphQLURL(baseURL), bytes.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", fmt.Sprint("token ", token))
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := (&http.Client{}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, errors.New(fmt.Sprint("non-200: ", resp.StatusCode))
+	}
+
+	var result graphQLContent
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Errors) > 0 {
+		return nil, errors.New(result.Errors[0].Message)
+	}
+	if result.Data.Node == nil {
+		return nil, errors.New(fmt.Sprint("couldn't find project item content: ", item.ContentNodeID))
+	}
+
+	node := result.Data.Node
+	return &webhookmodels.ProjectContent{
+		Number:      node.Number,
+		Title:       node.Title,
+		URL:         node.URL,
+		PullRequest: item.ContentType == "PullRequest",
+	}, nil
+}
+
+// graphQLURL returns the GraphQL endpoint next to the REST API: /api/graphql
+// on GHE, /graphql on github.com.
+func graphQLURL(baseURL string) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if strings.HasSuffix(baseURL, "/api/v3") {
+		return strings.TrimSuffix(baseURL, "/v3") + "/graphql"
+	}
+	return baseURL + "/graphql"
+}
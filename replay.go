@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mike-webster/repo-watcher/archive"
+	dispatchers "github.com/mike-webster/repo-watcher/dispatchers"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// replayDispatch sends messages to the repo's usual dispatcher
+	replayDispatch = "dispatch"
+	// replayChannel sends every message to the slack webhook in the request
+	replayChannel = "channel"
+	// replayDryRun only renders the messages and returns them
+	replayDryRun = "dry_run"
+)
+
+// replayLimit caps how many deliveries a single time range replay picks up.
+// A range with more than that is refused rather than cut short.  It's a
+// var so tests don't have to archive a thousand deliveries.
+var replayLimit = 1000
+
+// replayRequest picks which archived deliveries to run back through the
+// pipeline and where the resulting messages should go.
+type replayRequest struct {
+	Deliveries []string  `json:"deliveries"`
+	Repo       string    `json:"repo"`
+	Since      time.Time `json:"since"`
+	Until      time.Time `json:"until"`
+	Mode       string    `json:"mode"`
+	Channel    string    `json:"channel"`
+}
+
+type replayResult struct {
+	Delivery string `json:"delivery"`
+	Event    string `json:"event"`
+	Repo     string `json:"repo"`
+	Message  string `json:"message,omitempty"`
+	// Filtered is true when the watcher's policies dropped the event, as
+	// they would have when it was delivered
+	Filtered bool   `json:"filtered,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+func handlerReplay(ctx *gin.Context) {
+	deps := ctx.MustGet("deps").(*AppDependencies)
+	var req replayRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(CodeInvalid, fmt.Sprintf("{\"%v\":\"%v\"}", "reason", errInvalidBody))
+		return
+	}
+
+	results, err := replay(deps, &req, deps.logger)
+	if err != nil {
+		ctx.JSON(CodeInvalid, fmt.Sprintf("{\"%v\":\"%v\"}", "reason", err.Error()))
+		return
+	}
+
+	ctx.JSON(CodeOK, results)
+}
+
+// replay re-runs archived deliveries, oldest first.  A failure on one
+// delivery is recorded in its result and doesn't stop the rest.
+func replay(deps *AppDependencies, req *replayRequest, logger *logrus.Logger) ([]replayResult, error) {
+	if len(req.Mode) < 1 {
+		req.Mode = replayDispatch
+	}
+	switch req.Mode {
+	case replayDispatch, replayDryRun:
+	case replayChannel:
+		if len(req.Channel) < 1 {
+			return nil, errors.New("channel mode needs a channel")
+		}
+	default:
+		return nil, fmt.Errorf("unknown mode: %v", req.Mode)
+	}
+
+	ids := req.Deliveries
+	if len(ids) < 1 {
+		if len(req.Repo) < 1 && req.Since.IsZero() && req.Until.IsZero() {
+			return nil, errors.New("pick deliveries, a repo or a time range to replay")
+		}
+
+		found, err := deps.archive.Find(archive.Query{
+			Repo:  req.Repo,
+			Since: req.Since,
+			Until: req.Until,
+			Limit: replayLimit + 1,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(found) > replayLimit {
+			return nil, fmt.Errorf("more than %d deliveries match, narrow the repo or time range", replayLimit)
+		}
+		// Find returns the newest first
+		for i := len(found) - 1; i >= 0; i-- {
+			ids = append(ids, found[i].ID)
+		}
+	}
+
+	results := []replayResult{}
+	for _, id := range ids {
+		result := replayResult{Delivery: id}
+		err := replayDelivery(deps, req, &result, logger)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func replayDelivery(deps *AppDependencies, req *replayRequest, result *replayResult, logger *logrus.Logger) error {
+	d, err := deps.archive.Get(result.Delivery)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return errors.New(errNotFound)
+	}
+	result.Event = d.Event
+
+	p, ok := providers[d.Provider]
+	if !ok {
+		// deliveries archived before there were other providers
+		p = providers[providerGitHub]
+	}
+
+	event, err := parsePayload(p, d.Event, d.Body, logger)
+	if err != nil {
+		return err
+	}
+	if event == nil {
+		return nil
+	}
+	result.Repo = event.Repository()
+	if !allowReplay(event, logger) {
+		result.Filtered = true
+		return nil
+	}
+
+	// a plain context keeps replays from kicking off auto deploys
+	message, err := eventMessage(context.Background(), d.Event, event, logger)
+	if err != nil || len(message) < 1 {
+		return err
+	}
+	result.Message = message
+
+	logger.WithFields(logrus.Fields{
+		"event":    "replay",
+		"delivery": d.ID,
+		"mode":     req.Mode,
+		"repo":     result.Repo,
+	}).Info()
+
+	switch req.Mode {
+	case replayChannel:
+		sd := &dispatchers.SlackDispatcher{RepoName: result.Repo, URL: req.Channel}
+		return sd.SendMessage(message, logger)
+	case replayDispatch:
+		repo, channel := eventDestination(event)
+		return deps.dispatchers.ProcessChannelMessage(repo, channel, message, logger)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mike-webster/repo-watcher/storage"
+	webhookmodels "github.com/mike-webster/repo-watcher/webhookmodels"
+)
+
+const reviewBucket = "reviews"
+
+// reviewTTL is how long a pull request's reviews are kept after its last
+// review, in case we never hear that it was closed.
+const reviewTTL = 30 * 24 * time.Hour
+
+type reviewRecord struct {
+	// States holds each reviewer's latest approval or change request
+	States    map[string]string `json:"states"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// reviewStore remembers where each reviewer stands on a pull request so
+// review notifications can say how close it is to being merged.
+type reviewStore struct {
+	db *storage.DB
+	mu sync.Mutex
+}
+
+func newReviewStore(db *storage.DB) *reviewStore {
+	return &reviewStore{db: db}
+}
+
+func reviewKey(repo webhookmodels.Repository, number int) string {
+	return strings.ToLower(fmt.Sprint(repo.Slug(), "|", number))
+}
+
+// Record applies the review to its pull request and returns the tally
+// after it.  Comment-only reviews don't change where a reviewer stands;
+// dismissed reviews no longer count.
+func (rs *reviewStore) Record(e *webhookmodels.PullRequestReviewEventPayload) (*webhookmodels.ReviewTally, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	key := reviewKey(e.Repo, e.PullRequest.Number)
+	var rec reviewRecord
+	_, err := rs.db.Get(reviewBucket, key, &rec)
+	if err != nil {
+		return nil, err
+	}
+	if rec.States == nil {
+		rec.States = map[string]string{}
+	}
+
+	reviewer := strings.ToLower(e.Review.User.Login)
+	state := strings.ToLower(e.Review.State)
+	switch {
+	case e.Action == "dismissed" || state == webhookmodels.ReviewDismissed:
+		delete(rec.States, reviewer)
+	case e.Action != "submitted":
+	case state == webhookmodels.ReviewApproved || state == webhookmodels.ReviewChangesRequested:
+		rec.States[reviewer] = state
+	}
+
+	tally := &webhookmodels.ReviewTally{}
+	for _, s := range rec.States {
+		switch s {
+		case webhookmodels.ReviewApproved:
+			tally.Approvals++
+		case webhookmodels.ReviewChangesRequested:
+			tally.ChangeRequests++
+		}
+	}
+
+	rec.UpdatedAt = time.Now()
+	return tally, rs.db.Put(reviewBucket, key, rec)
+}
+
+// Forget drops a pull request's reviews, e.g. once it's closed.
+func (rs *reviewStore) Forget(repo webhookmodels.Repository, number int) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	return rs.db.Delete(reviewBucket, reviewKey(repo, number))
+}
+
+// Prune removes pull requests that haven't been reviewed within the TTL and
+// returns how many were removed.
+func (rs *reviewStore) Prune() (int, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	expired := []string{}
+	err := rs.db.ForEach(reviewBucket, func(key string, raw []byte) error {
+		var rec reviewRecord
+		if err := json.Unmarshal(raw, &rec); err != nil || time.Since(rec.UpdatedAt) >= reviewTTL {
+			expired = append(expired, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, key := range expired {
+		if err := rs.db.Delete(reviewBucket, key); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(expired), nil
+}
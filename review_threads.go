@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/mike-webster/repo-watcher/storage"
+	webhookmodels "github.com/mike-webster/repo-watcher/webhookmodels"
+	"github.com/sirupsen/logrus"
+)
+
+const threadBucket = "review_threads"
+
+// heldThread is a conversation waiting out its window.  It's saved to the
+// store so it's still announced if the process restarts in the meantime;
+// the delivery has already been acknowledged and won't be sent again.
+type heldThread struct {
+	EventName string                                              `json:"event_name"`
+	Event     *webhookmodels.PullRequestReviewCommentEventPayload `json:"event"`
+	Replies   []webhookmodels.ReviewComment                       `json:"replies"`
+	HeldAt    time.Time                                           `json:"held_at"`
+}
+
+// reviewThreads holds new review comments for a short window so replies
+// that come in during it are announced with the comment they answer,
+// instead of one message each.
+type reviewThreads struct {
+	window   time.Duration
+	announce func(j job)
+	// db is nil when the threads are only kept in memory
+	db      *storage.DB
+	logger  *logrus.Logger
+	mu      sync.Mutex
+	pending map[string]*heldThread
+}
+
+func newReviewThreads(window time.Duration, db *storage.DB, logger *logrus.Logger, announce func(j job)) *reviewThreads {
+	return &reviewThreads{
+		window:   window,
+		announce: announce,
+		db:       db,
+		logger:   logger,
+		pending:  map[string]*heldThread{},
+	}
+}
+
+// Hold returns true if the job is a review comment that's being held to be
+// grouped.  The first comment of a conversation is announced, with any
+// replies, once the window is up.
+func (rt *reviewThreads) Hold(j job) bool {
+	e, ok := j.event.(*webhookmodels.PullRequestReviewCommentEventPayload)
+	if !ok || e.Action != "created" || rt.window <= 0 {
+		return false
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	key := e.ThreadKey()
+	if held, ok := rt.pending[key]; ok {
+		held.Event.Replies = append(held.Event.Replies, e.Comment)
+		rt.save(key, held)
+		return true
+	}
+
+	held := &heldThread{EventName: j.eventName, Event: e, HeldAt: time.Now()}
+	rt.pending[key] = held
+	rt.save(key, held)
+	rt.schedule(key, j, rt.window)
+	return true
+}
+
+// Resume picks the conversations that were held when the process stopped
+// back up.  Ones whose window is already up are announced straight away.
+func (rt *reviewThreads) Resume() error {
+	if rt.db == nil {
+		return nil
+	}
+
+	held := []*heldThread{}
+	err := rt.db.ForEach(threadBucket, func(key string, raw []byte) error {
+		var h heldThread
+		err := json.Unmarshal(raw, &h)
+		if err != nil {
+			return err
+		}
+
+		held = append(held, &h)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	for _, h := range held {
+		h.Event.Replies = h.Replies
+		key := h.Event.ThreadKey()
+		rt.pending[key] = h
+		j := job{ctx: context.Background(), eventName: h.EventName, event: h.Event}
+		rt.schedule(key, j, time.Until(h.HeldAt.Add(rt.window)))
+	}
+	return nil
+}
+
+// schedule announces the conversation once wait is up.  The caller holds
+// the lock.
+func (rt *reviewThreads) schedule(key string, j job, wait time.Duration) {
+	time.AfterFunc(wait, func() {
+		rt.mu.Lock()
+		delete(rt.pending, key)
+		rt.forget(key)
+		rt.mu.Unlock()
+
+		rt.announce(j)
+	})
+}
+
+// save writes the conversation to the store.  The caller holds the lock.
+func (rt *reviewThreads) save(key string, held *heldThread) {
+	if rt.db == nil {
+		return
+	}
+
+	held.Replies = held.Event.Replies
+	if err := rt.db.Put(threadBucket, key, held); err != nil {
+		rt.logger.WithFields(logrus.Fields{
+			"event":  "failed_thread_save",
+			"error":  err,
+			"thread": key,
+		}).Warn("couldn't save held review comment, it's only kept in memory")
+	}
+}
+
+// forget removes the conversation from the store.  The caller holds the
+// lock.
+func (rt *reviewThreads) forget(key string) {
+	if rt.db == nil {
+		return
+	}
+
+	if err := rt.db.Delete(threadBucket, key); err != nil {
+		rt.logger.WithFields(logrus.Fields{
+			"event":  "failed_thread_delete",
+			"error":  err,
+			"thread": key,
+		}).Warn("couldn't remove announced review comment, it may be announced again")
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	env "github.com/mike-webster/repo-watcher/env"
+	webhookmodels "github.com/mike-webster/repo-watcher/webhookmodels"
+)
+
+// auditRepo is the dispatcher repo name for the audit webhook.  It can't
+// clash with a real repo name.
+const auditRepo = "*audit*"
+
+// eventDestination returns the dispatcher repo and channel the event should
+// be announced in.  Audit events go to the audit webhook when there is one.
+func eventDestination(event webhookmodels.Event) (string, string) {
+	if _, ok := event.(webhookmodels.AuditEvent); ok && len(env.GetConfig().AuditWebhook) > 0 {
+		return auditRepo, ""
+	}
+	return event.Repository(), eventChannel(event)
+}
+
+// eventChannel returns the watcher's named channel the event should be
+// announced in, or an empty string for the watcher's webhook.
+func eventChannel(event webhookmodels.Event) string {
+	w := env.GetConfig().Watchers.Select(event.Repository())
+	if w == nil {
+		return ""
+	}
+
+	switch e := event.(type) {
+	case webhookmodels.DiscussionEvent:
+		return w.DiscussionChannel(e.Category())
+	case webhookmodels.SecurityAlert:
+		return w.Security.Channel
+	}
+
+	return ""
+}
@@ -2,11 +2,16 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/mike-webster/repo-watcher/archive"
+	env "github.com/mike-webster/repo-watcher/env"
 	"github.com/mike-webster/repo-watcher/keys"
 	webhookmodels "github.com/mike-webster/repo-watcher/webhookmodels"
 	"github.com/sirupsen/logrus"
@@ -28,8 +33,10 @@ func (s *Server) Run() error {
 }
 
 type ghRequestHeader struct {
-	Event  string `header:"X-GitHub-Event" binding:"required"`
-	Secret string `header:"X-Hub-Signature" binding:"required"`
+	Event     string `header:"X-GitHub-Event" binding:"required"`
+	Secret    string `header:"X-Hub-Signature"`
+	Secret256 string `header:"X-Hub-Signature-256"`
+	Delivery  string `header:"X-GitHub-Delivery"`
 }
 
 func (ghrh *ghRequestHeader) ToString() string {
@@ -47,7 +54,22 @@ func SetupServer(port string, deps *AppDependencies) *Server {
 
 	v1 := router.Group("/v1")
 	{
-		v1.POST("/github", handlerGitHub)
+		v1.POST("/github", handlerWebhook(providers[providerGitHub]))
+		v1.POST("/gitlab", handlerWebhook(providers[providerGitLab]))
+		v1.POST("/bitbucket", handlerWebhook(providers[providerBitbucket]))
+		v1.POST("/gitea", handlerWebhook(providers[providerGitea]))
+		v1.GET("/events", requireAdmin(), handlerListEvents)
+		v1.GET("/events/:delivery", requireAdmin(), handlerGetEvent)
+		v1.POST("/replay", requireAdmin(), handlerReplay)
+	}
+
+	admin := v1.Group("/admin", requireAdmin())
+	{
+		admin.GET("/dead_letters", handlerListDeadLetters)
+		admin.POST("/dead_letters/:id/retry", handlerRetryDeadLetter)
+		admin.DELETE("/dead_letters", handlerPurgeDeadLetters)
+		admin.DELETE("/dead_letters/:id", handlerDeleteDeadLetter)
+		admin.DELETE("/deliveries/:delivery", handlerForgetDelivery)
 	}
 
 	return &Server{
@@ -60,164 +82,301 @@ func handlerHealtcheck(ctx *gin.Context) {
 	ctx.JSON(CodeOK, fmt.Sprintf("{\"%v\":\"%v\"}", "message", "ok"))
 }
 
-func handlerGitHub(ctx *gin.Context) {
-	deps := ctx.MustGet("deps").(*AppDependencies)
-	hdr := &ghRequestHeader{}
-	err := ctx.BindHeader(hdr)
-	if err != nil {
-		deps.logger.WithField("error", err).Error("invalid request header -- could not bind")
-
-		errs := strings.Split(err.Error(), "\n")
-		msg := ""
-		for _, e := range errs {
-			v := strings.Replace(e, "Key: ", "", 1)
-			msg += fmt.Sprintf("\"%v\":\"%v\",", "reason", v)
-		}
-		msg = fmt.Sprintf("{%v}", strings.TrimRight(msg, ","))
-		ctx.JSON(CodeInvalid, msg)
-		return
-	}
+// provider is a source of webhooks.  Each one knows how to read and
+// authenticate its own deliveries and which payload types its events decode
+// into; everything after that is shared.
+type provider interface {
+	// Name identifies the provider in the archive
+	Name() string
+	// Headers binds the provider's request headers, returning the event
+	// name and the delivery ID (if it sends one)
+	Headers(ctx *gin.Context) (string, string, error)
+	// Repo pulls the name watchers are keyed on out of the raw body
+	Repo(body []byte) string
+	// Verify authenticates the raw body with the watcher's secrets
+	Verify(ctx *gin.Context, w *env.Watcher, body []byte) error
+	// Payload returns an empty payload for the event to be decoded into.
+	// Events that are known but not announced return nil and true.
+	Payload(eventName string) (webhookmodels.Event, bool)
+}
 
-	summary, repo, err := parseEventMessage(ctx, hdr.Event, deps.logger)
-	if err != nil {
-		deps.logger.WithField("error", err).Error("couldn't parse event message")
-		errs := strings.Split(err.Error(), "\n")
-		msg := ""
-		for _, e := range errs {
-			v := strings.Replace(e, "Key: ", "", 1)
-			msg += fmt.Sprintf("\"%v\":\"%v\",", "reason", v)
-		}
-		msg = fmt.Sprintf("{%v}", strings.TrimRight(msg, ","))
-		ctx.JSON(CodeInvalid, msg)
-		return
-	}
+// providers are looked up by name when replaying archived deliveries.
+var providers = map[string]provider{
+	providerGitHub:    &gitHubProvider{},
+	providerGitLab:    &gitLabProvider{},
+	providerBitbucket: &bitbucketProvider{},
+	providerGitea:     &giteaProvider{},
+}
 
-	if len(summary) > 0 {
-		err := deps.dispatchers.ProcessMessage(repo, summary, deps.logger)
+// handlerWebhook receives deliveries from the provider.
+func handlerWebhook(p provider) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		deps := ctx.MustGet("deps").(*AppDependencies)
+		eventName, delivery, err := p.Headers(ctx)
 		if err != nil {
-			deps.logger.WithFields(logrus.Fields{
-				"error":   err,
-				"payload": summary,
-			}).Error("error sending message")
-			ctx.Status(500)
+			deps.logger.WithField("error", err).Error("invalid request header -- could not bind")
+			ctx.JSON(CodeInvalid, reasons(err))
 			return
 		}
-	}
-	ctx.Status(CodeNoContent)
-}
 
-func parseEvent(ctx *gin.Context, eventName string) (webhookmodels.Event, error) {
-	switch eventName {
-	case "create":
-		var event webhookmodels.CreateEventPayload
-		err := ctx.BindJSON(&event)
-		if err != nil {
-			return nil, err
-		}
-		return &event, nil
-	case "gollum":
-		var event webhookmodels.GollumEventPayload
-		err := ctx.BindJSON(&event)
+		body, err := ctx.GetRawData()
 		if err != nil {
-			return nil, err
-		}
-		return &event, nil
-	case "issue_comment":
-		var event webhookmodels.IssueCommentEventPayload
-		err := ctx.BindJSON(&event)
-		if err != nil {
-			return nil, err
-		}
-		return &event, nil
-	case "issues":
-		var event webhookmodels.IssuesEventPayload
-		err := ctx.BindJSON(&event)
-		if err != nil {
-			return nil, err
-		}
-		return &event, nil
-	case "project_card":
-		var event webhookmodels.ProjectCardEventPayload
-		err := ctx.BindJSON(&event)
-		if err != nil {
-			return nil, err
+			deps.logger.WithField("error", err).Error("couldn't read request body")
+			ctx.JSON(CodeInvalid, fmt.Sprintf("{\"%v\":\"%v\"}", "reason", errInvalidBody))
+			return
 		}
-		return &event, nil
-	case "project_column":
-		var event webhookmodels.ProjectColumnEventPayload
-		err := ctx.BindJSON(&event)
+
+		repoName := p.Repo(body)
+		watcher := env.GetConfig().Watchers.Select(repoName)
+		err = p.Verify(ctx, watcher, body)
 		if err != nil {
-			return nil, err
+			deps.logger.WithFields(logrus.Fields{
+				"event":      "invalid_signature",
+				"error":      err,
+				"provider":   p.Name(),
+				"git_event":  eventName,
+				"repo":       repoName,
+				"user_agent": ctx.Request.UserAgent(),
+			}).Warn("couldn't verify request signature")
+			ctx.JSON(CodeUnauth, fmt.Sprintf("{\"%v\":\"%v\"}", "reason", errInvalidSecret))
+			return
 		}
-		return &event, nil
-	case "pull_request":
-		var event webhookmodels.PullRequestEventPayload
-		err := ctx.BindJSON(&event)
-		if err != nil {
-			return nil, err
+
+		if p.Name() == providerGitHub {
+			rememberInstallation(body)
 		}
-		return &event, nil
-	case "pull_request_review_comment":
-		var event webhookmodels.PullRequestReviewCommentEventPayload
-		err := ctx.BindJSON(&event)
+
+		event, err := parsePayload(p, eventName, body, deps.logger)
 		if err != nil {
-			return nil, err
+			deps.logger.WithField("error", err).Error("couldn't parse event message")
+			ctx.JSON(CodeInvalid, reasons(err))
+			return
 		}
-		return &event, nil
-	case "pull_request_review":
-		var event webhookmodels.PullRequestReviewEventPayload
-		err := ctx.BindJSON(&event)
+
+		acceptEvent(ctx, deps, p.Name(), delivery, eventName, event, body)
+	}
+}
+
+// parsePayload decodes the raw body into the provider's payload type for
+// the event and validates it.  Events that aren't announced return a nil
+// event and no error.
+func parsePayload(p provider, eventName string, body []byte, logger *logrus.Logger) (webhookmodels.Event, error) {
+	event, known := p.Payload(eventName)
+	if !known {
+		logger.WithFields(logrus.Fields{
+			"event":    "unknown_event",
+			"provider": p.Name(),
+			"value":    eventName,
+		}).Error("unknown event name from provider")
+		return nil, nil
+	}
+	if event == nil {
+		return nil, nil
+	}
+
+	err := binding.JSON.BindBody(body, event)
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// acceptEvent queues a verified, parsed event to be announced and archives
+// the delivery.  Deliveries that were already accepted are acknowledged
+// without being queued again.
+func acceptEvent(ctx *gin.Context, deps *AppDependencies, provider string, delivery string, eventName string, event webhookmodels.Event, body []byte) {
+	// this is just skipping pings and unknown events for now
+	if event == nil {
+		ctx.Status(CodeNoContent)
+		return
+	}
+
+	if len(delivery) > 0 {
+		isNew, err := deps.deliveries.Claim(delivery)
 		if err != nil {
-			return nil, err
+			// better to announce twice than not at all
+			deps.logger.WithFields(logrus.Fields{
+				"error":    err,
+				"delivery": delivery,
+			}).Error("couldn't check delivery history")
+		} else if !isNew {
+			deps.logger.WithFields(logrus.Fields{
+				"event":      "duplicate_delivery",
+				"delivery":   delivery,
+				"event_name": eventName,
+				"repo":       event.Repository(),
+			}).Info("skipping delivery that was already processed")
+			ctx.JSON(CodeOK, fmt.Sprintf("{\"%v\":\"%v\"}", "message", msgDuplicateDelivery))
+			return
 		}
-		return &event, nil
-	case "push":
-		var event webhookmodels.PushEventPayload
-		err := ctx.BindJSON(&event)
-		if err != nil {
-			return nil, err
+	}
+
+	queued := deps.queue.Enqueue(job{
+		ctx:       context.WithValue(context.Background(), keys.AutoMerge, autoMergeEnabled(ctx)),
+		eventName: eventName,
+		event:     event,
+	})
+	if !queued {
+		deps.logger.WithFields(logrus.Fields{
+			"event":       "queue_full",
+			"event_name":  eventName,
+			"repo":        event.Repository(),
+			"queue_depth": deps.queue.Depth(),
+		}).Error("dropping event, queue is full")
+		if len(delivery) > 0 {
+			// let the redelivery through once there's room
+			deps.deliveries.Forget(delivery)
 		}
-		return &event, nil
-	case "ping":
-		// returning an object to force proceesing to be skipped
-		return &webhookmodels.CreateEventPayload{
-			Repo: webhookmodels.Repository{
-				Name: "skip",
-			},
-		}, nil
-	case "status":
-		// returning an object to force proceesing to be skipped
-		return &webhookmodels.CreateEventPayload{
-			Repo: webhookmodels.Repository{
-				Name: "skip",
-			},
-		}, nil
-	default:
-		defaultLogger(ctx).WithFields(logrus.Fields{
-			"event": "unknown_github_event",
-			"value": eventName,
-		}).Error("unknown event name from github")
-		return nil, nil
+		ctx.Header("Retry-After", "30")
+		ctx.JSON(CodeUnavailable, fmt.Sprintf("{\"%v\":\"%v\"}", "reason", errQueueFull))
+		return
+	}
+
+	if len(delivery) < 1 {
+		delivery = fmt.Sprint("local-", time.Now().UnixNano())
+	}
+	err := deps.archive.Save(archive.NewDelivery(provider, delivery, eventName, event, body))
+	if err != nil {
+		deps.logger.WithFields(logrus.Fields{
+			"error":    err,
+			"delivery": delivery,
+		}).Error("couldn't archive delivery")
 	}
+
+	ctx.Status(CodeAccepted)
+}
+
+// reasons formats binding and validation errors for the response body.
+func reasons(err error) string {
+	errs := strings.Split(err.Error(), "\n")
+	msg := ""
+	for _, e := range errs {
+		v := strings.Replace(e, "Key: ", "", 1)
+		msg += fmt.Sprintf("\"%v\":\"%v\",", "reason", v)
+	}
+	return fmt.Sprintf("{%v}", strings.TrimRight(msg, ","))
+}
+
+// gitHubProvider receives webhooks from GitHub Enterprise.
+type gitHubProvider struct{}
+
+// gitHubEvents maps X-GitHub-Event names to their payloads.  A nil entry is
+// an event that's acknowledged but not announced.
+var gitHubEvents = map[string]func() webhookmodels.Event{
+	"create":                      func() webhookmodels.Event { return &webhookmodels.CreateEventPayload{} },
+	"gollum":                      func() webhookmodels.Event { return &webhookmodels.GollumEventPayload{} },
+	"issue_comment":               func() webhookmodels.Event { return &webhookmodels.IssueCommentEventPayload{} },
+	"issues":                      func() webhookmodels.Event { return &webhookmodels.IssuesEventPayload{} },
+	"project_card":                func() webhookmodels.Event { return &webhookmodels.ProjectCardEventPayload{} },
+	"project_column":              func() webhookmodels.Event { return &webhookmodels.ProjectColumnEventPayload{} },
+	"pull_request":                func() webhookmodels.Event { return &webhookmodels.PullRequestEventPayload{} },
+	"pull_request_review_comment": func() webhookmodels.Event { return &webhookmodels.PullRequestReviewCommentEventPayload{} },
+	"pull_request_review":         func() webhookmodels.Event { return &webhookmodels.PullRequestReviewEventPayload{} },
+	"push":                        func() webhookmodels.Event { return &webhookmodels.PushEventPayload{} },
+	"release":                     func() webhookmodels.Event { return &webhookmodels.ReleaseEventPayload{} },
+	"status":                      func() webhookmodels.Event { return &webhookmodels.StatusEventPayload{} },
+	"check_run":                   func() webhookmodels.Event { return &webhookmodels.CheckRunEventPayload{} },
+	"check_suite":                 func() webhookmodels.Event { return &webhookmodels.CheckSuiteEventPayload{} },
+	"workflow_run":                func() webhookmodels.Event { return &webhookmodels.WorkflowRunEventPayload{} },
+	"workflow_job":                func() webhookmodels.Event { return &webhookmodels.WorkflowJobEventPayload{} },
+	"delete":                      func() webhookmodels.Event { return &webhookmodels.DeleteEventPayload{} },
+	"deployment":                  func() webhookmodels.Event { return &webhookmodels.DeploymentEventPayload{} },
+	"deployment_status":           func() webhookmodels.Event { return &webhookmodels.DeploymentStatusEventPayload{} },
+	"discussion":                  func() webhookmodels.Event { return &webhookmodels.DiscussionEventPayload{} },
+	"discussion_comment":          func() webhookmodels.Event { return &webhookmodels.DiscussionCommentEventPayload{} },
+	"dependabot_alert":            func() webhookmodels.Event { return &webhookmodels.DependabotAlertEventPayload{} },
+	"code_scanning_alert":         func() webhookmodels.Event { return &webhookmodels.CodeScanningAlertEventPayload{} },
+	"secret_scanning_alert":       func() webhookmodels.Event { return &webhookmodels.SecretScanningAlertEventPayload{} },
+	"member":                      func() webhookmodels.Event { return &webhookmodels.MemberEventPayload{} },
+	"team_add":                    func() webhookmodels.Event { return &webhookmodels.TeamAddEventPayload{} },
+	"repository":                  func() webhookmodels.Event { return &webhookmodels.RepositoryEventPayload{} },
+	"branch_protection_rule":      func() webhookmodels.Event { return &webhookmodels.BranchProtectionRuleEventPayload{} },
+	"projects_v2_item":            func() webhookmodels.Event { return &webhookmodels.ProjectsV2ItemEventPayload{} },
+	"commit_comment":              func() webhookmodels.Event { return &webhookmodels.CommitCommentEventPayload{} },
+	"milestone":                   func() webhookmodels.Event { return &webhookmodels.MilestoneEventPayload{} },
+	"label":                       func() webhookmodels.Event { return &webhookmodels.LabelEventPayload{} },
+	"ping":                        nil,
+}
+
+func (ghp *gitHubProvider) Name() string {
+	return providerGitHub
 }
 
-func parseEventMessage(ctx *gin.Context, eventName string, logger *logrus.Logger) (string, string, error) {
-	event, err := parseEvent(ctx, eventName)
+func (ghp *gitHubProvider) Headers(ctx *gin.Context) (string, string, error) {
+	hdr := &ghRequestHeader{}
+	err := ctx.BindHeader(hdr)
 	if err != nil {
 		return "", "", err
 	}
 
-	// this is just skipping the initial "ping" for now
-	if event.Repository() == "skip" {
-		return "", "", nil
+	return hdr.Event, hdr.Delivery, nil
+}
+
+// Repo pulls the repository name out of a raw webhook body so the matching
+// watcher can be found before the payload is trusted.
+func (ghp *gitHubProvider) Repo(body []byte) string {
+	sBody := struct {
+		Action     string `json:"action"`
+		Repository struct {
+			Name string `json:"name"`
+		} `json:"repository"`
+		Organization struct {
+			Login string `json:"login"`
+		} `json:"organization"`
+		Changes struct {
+			Repository struct {
+				Name webhookmodels.ChangedValue `json:"name"`
+			} `json:"repository"`
+		} `json:"changes"`
+	}{}
+
+	err := json.Unmarshal(body, &sBody)
+	if err != nil {
+		return ""
 	}
 
+	if sBody.Action == "renamed" && len(sBody.Changes.Repository.Name.From) > 0 {
+		// a rename carries the new name, but the watcher is under the old one
+		return sBody.Changes.Repository.Name.From
+	}
+	if len(sBody.Repository.Name) < 1 {
+		// org-level events like projects_v2_item don't carry a repository,
+		// so they're watched under the organization's login
+		return sBody.Organization.Login
+	}
+	return sBody.Repository.Name
+}
+
+func (ghp *gitHubProvider) Verify(ctx *gin.Context, w *env.Watcher, body []byte) error {
+	return verifySignature(w, body, ctx.GetHeader("X-Hub-Signature-256"), ctx.GetHeader("X-Hub-Signature"))
+}
+
+func (ghp *gitHubProvider) Payload(eventName string) (webhookmodels.Event, bool) {
+	newEvent, ok := gitHubEvents[eventName]
+	if !ok || newEvent == nil {
+		return nil, ok
+	}
+
+	return newEvent(), true
+}
+
+// eventMessage builds the message to announce for the event, resolving the
+// sender's name along the way.  An empty message means there is nothing to
+// announce.
+func eventMessage(ctx context.Context, eventName string, event webhookmodels.Event, logger *logrus.Logger) (string, error) {
+	enrichEvent(event, logger)
+
 	if len(event.ToString()) < 1 {
 		logger.WithFields(logrus.Fields{
 			"event":      "skipping_notification",
 			"event_name": eventName,
 		}).Warn("no message returned, skipping notify")
-		return "", "", nil
+		return "", nil
+	}
+
+	if named, ok := event.(webhookmodels.NamedEvent); ok && len(named.DisplayName()) > 0 {
+		// not a GHE user, so there's nobody to look up
+		return fmt.Sprint(named.DisplayName(), " ", event.ToString()), nil
 	}
 
 	name, err := getNameFromUsername(event.Username())
@@ -228,7 +387,7 @@ func parseEventMessage(ctx *gin.Context, eventName string, logger *logrus.Logger
 			"username": event.Username(),
 		}).Warn("couldnt retrieve name from username")
 
-		return fmt.Sprint(event.Username(), " ", event.ToString()), event.Repository(), nil
+		return fmt.Sprint(event.Username(), " ", event.ToString()), nil
 	}
 
 	if autoMergeEnabled(ctx) {
@@ -267,7 +426,7 @@ func parseEventMessage(ctx *gin.Context, eventName string, logger *logrus.Logger
 		}
 	}
 
-	return fmt.Sprint(name, " ", event.ToString()), event.Repository(), nil
+	return fmt.Sprint(name, " ", event.ToString()), nil
 }
 
 func autoMergeEnabled(ctx context.Context) bool {
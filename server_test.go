@@ -2,19 +2,34 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"hash"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/bmizerany/assert"
 	"github.com/gin-gonic/gin"
+	"github.com/mike-webster/repo-watcher/archive"
 	dispatchers "github.com/mike-webster/repo-watcher/dispatchers"
 	"github.com/mike-webster/repo-watcher/env"
+	"github.com/mike-webster/repo-watcher/storage"
 	"github.com/mike-webster/repo-watcher/webhookmodels"
 )
 
+const testSecret = "test-secret"
+const testAdminToken = "test-admin-token"
+
 type testDeps struct {
 	Router    *gin.Engine
 	Deps      *AppDependencies
@@ -27,11 +42,44 @@ func TestMain(t *testing.T) {
 	testHealthcheck(t, deps)
 	testGithub(t, deps)
 	testParseEvent(t, deps)
+	testQueueFull(t)
+	testDeadLetters(t, deps)
+	testDuplicateDelivery(t, deps)
+	testEventArchive(t, deps)
+	testReplay(t, deps)
+	testGitLab(t, deps)
+	testBitbucket(t, deps)
+	testGitea(t, deps)
+	testReleaseChangelog(t)
+	testCIPolicy(t, deps)
+	testWorkflows(t, deps)
+	testDeployments(t, deps)
+	testProtectedRefs(t, deps)
+	testPushCommits(t)
+	testPullRequestActions(t, deps)
+	testEdits(t, deps)
+	testReviewComments(t, deps)
+	testReviews(t, deps)
+	testDiscussions(t, deps)
+	testSecurityAlerts(t, deps)
+	testAuditEvents(t, deps)
+	testProjects(t, deps)
+	testRepoHousekeeping(t, deps)
 }
 
 func testSetup() *testDeps {
 	cfg := env.GetConfig()
 	testWatch := cfg.Watchers.Select("test")
+
+	dir, err := ioutil.TempDir("", "repo-watcher")
+	if err != nil {
+		panic(err)
+	}
+	store, err := storage.Open(filepath.Join(dir, "test.db"))
+	if err != nil {
+		panic(err)
+	}
+
 	deps := AppDependencies{
 		logger: defaultLogger(nil),
 		dispatchers: dispatchers.Dispatchers{
@@ -41,7 +89,28 @@ func testSetup() *testDeps {
 				MakeCalls: cfg.MakeTestCalls,
 			},
 		},
+		store:       store,
+		deadLetters: &dispatchers.DeadLetterStore{DB: store},
+		deliveries:  newDeliveryStore(store, time.Hour),
+		archive:     &archive.Store{DB: store},
+		ciStates:    newCIStateStore(store),
+		deployments: newDeploymentStore(store),
+		reviews:     newReviewStore(store),
+	}
+	for name, url := range testWatch.Channels {
+		deps.dispatchers = append(deps.dispatchers, &dispatchers.TestDispatcher{
+			RepoName:    testWatch.Repo,
+			ChannelName: name,
+			URL:         url,
+			MakeCalls:   cfg.MakeTestCalls,
+		})
 	}
+	deps.dispatchers = append(deps.dispatchers, &dispatchers.TestDispatcher{
+		RepoName: auditRepo,
+		URL:      cfg.AuditWebhook,
+	})
+	deps.queue = newWorkerPool(cfg.Workers, cfg.QueueSize, time.Duration(cfg.ThreadSecs)*time.Second, &deps)
+	deps.queue.Start()
 	server := SetupServer("3199", &deps)
 	return &testDeps{
 		Router:    server.Engine,
@@ -64,6 +133,7 @@ func testGithub(t *testing.T, deps *testDeps) {
 		ExpectedMessage string
 		Headers         map[string]string
 		Body            interface{}
+		Unsigned        bool
 	}{
 		{
 			Name:            "TestEmptyHeaders",
@@ -71,15 +141,17 @@ func testGithub(t *testing.T, deps *testDeps) {
 			Method:          "POST",
 			ExpectedCode:    CodeInvalid,
 			Headers:         map[string]string{},
-			ExpectedMessage: `"{\"reason\":\"'ghRequestHeader.Event' Error:Field validation for 'Event' failed on the 'required' tag\",\"reason\":\"'ghRequestHeader.Secret' Error:Field validation for 'Secret' failed on the 'required' tag\"}"`,
+			ExpectedMessage: `"{\"reason\":\"'ghRequestHeader.Event' Error:Field validation for 'Event' failed on the 'required' tag\"}"`,
 		},
 		{
-			Name:            "TestEmptyHeaderSecret",
+			Name:            "TestMissingSignature",
 			Path:            "/v1/github",
 			Method:          "POST",
-			ExpectedCode:    CodeInvalid,
-			Headers:         map[string]string{"X-GitHub-Event": "test"},
-			ExpectedMessage: `"{\"reason\":\"'ghRequestHeader.Secret' Error:Field validation for 'Secret' failed on the 'required' tag\"}"`,
+			ExpectedCode:    CodeUnauth,
+			ExpectedMessage: `"{\"reason\":\"Invalid request secret\"}"`,
+			Headers:         map[string]string{"X-GitHub-Event": "push"},
+			Body:            json.RawMessage(`{"ref":"test/ref","repository":{"name":"test"}}`),
+			Unsigned:        true,
 		},
 		{
 			Name:            "TestEmptyHeaderEvent",
@@ -96,13 +168,64 @@ func testGithub(t *testing.T, deps *testDeps) {
 			ExpectedCode:    CodeInvalid,
 			ExpectedMessage: `"{\"reason\":\"'PushEventPayload.Ref' Error:Field validation for 'Ref' failed on the 'required' tag\"}"`,
 			Headers:         map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature": "test"},
-			Body:            webhookmodels.PushEventPayload{},
+			Body: webhookmodels.PushEventPayload{
+				Repo: webhookmodels.Repository{
+					Name: "test",
+				},
+			},
+		},
+		{
+			Name:            "TestBadSignature",
+			Path:            "/v1/github",
+			Method:          "POST",
+			ExpectedCode:    CodeUnauth,
+			ExpectedMessage: `"{\"reason\":\"Invalid request secret\"}"`,
+			Headers:         map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature": "test", "X-Hub-Signature-256": "sha256=" + sign(sha256.New, "wrong-secret", []byte("{}"))},
+			Body: webhookmodels.PushEventPayload{
+				Ref: "test/ref",
+				Repo: webhookmodels.Repository{
+					Name: "test",
+				},
+			},
+			Unsigned: true,
+		},
+		{
+			Name:            "TestUnknownRepo",
+			Path:            "/v1/github",
+			Method:          "POST",
+			ExpectedCode:    CodeUnauth,
+			ExpectedMessage: `"{\"reason\":\"Invalid request secret\"}"`,
+			Headers:         map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature": "test", "Content-Type": "application/json"},
+			Body: webhookmodels.PushEventPayload{
+				Ref: "test/ref",
+				Repo: webhookmodels.Repository{
+					Name: "not-watched",
+				},
+			},
+		},
+		{
+			Name:            "TestSHA1NotAllowed",
+			Path:            "/v1/github",
+			Method:          "POST",
+			ExpectedCode:    CodeUnauth,
+			ExpectedMessage: `"{\"reason\":\"Invalid request secret\"}"`,
+			Headers:         map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature": "sha1=" + sign(sha1.New, testSecret, []byte(`{"ref":"test/ref","repository":{"name":"test"}}`))},
+			Body:            json.RawMessage(`{"ref":"test/ref","repository":{"name":"test"}}`),
+			Unsigned:        true,
+		},
+		{
+			Name:         "TestSHA256Only",
+			Path:         "/v1/github",
+			Method:       "POST",
+			ExpectedCode: CodeAccepted,
+			Headers:      map[string]string{"X-GitHub-Event": "push", "Content-Type": "application/json"},
+			Body:         json.RawMessage(`{"ref":"test/ref","repository":{"name":"test"},"sender":{"login":"mwebster"}}`),
 		},
 		{
 			Name:         "TestSuccess",
 			Path:         "/v1/github",
 			Method:       "POST",
-			ExpectedCode: CodeNoContent,
+			ExpectedCode: CodeAccepted,
 			Headers:      map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature": "push", "Content-Type": "application/json"},
 			Body: webhookmodels.PushEventPayload{
 				Ref: "test/ref",
@@ -124,7 +247,11 @@ func testGithub(t *testing.T, deps *testDeps) {
 				if err != nil {
 					assert.Equal(t, nil, err)
 				}
-				resp := performRequest(deps.Router, c.Method, c.Path, c.Headers, body)
+				headers := c.Headers
+				if !c.Unsigned {
+					headers = signedHeaders(c.Headers, body)
+				}
+				resp := performRequest(deps.Router, c.Method, c.Path, headers, body)
 				assert.Equal(t, c.ExpectedCode, resp.Code, resp.Body.String())
 				if len(c.ExpectedMessage) > 0 {
 					assert.Equal(t, c.ExpectedMessage, resp.Body.String(), fmt.Sprintf("%v \n\t\t\t!=\n%v", c.ExpectedMessage, resp.Body.String()))
@@ -134,6 +261,559 @@ func testGithub(t *testing.T, deps *testDeps) {
 	})
 }
 
+func testQueueFull(t *testing.T) {
+	deps := testSetup()
+	// a queue nobody is reading from with no room in it
+	deps.Deps.queue = &workerPool{jobs: make(chan job), deps: deps.Deps}
+
+	body := []byte(`{"ref":"test/ref","repository":{"name":"test"},"sender":{"login":"mwebster"}}`)
+	headers := signedHeaders(map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature": "push", "Content-Type": "application/json"}, body)
+	resp := performRequest(deps.Router, "POST", "/v1/github", headers, body)
+	assert.Equal(t, CodeUnavailable, resp.Code, resp.Body.String())
+	assert.Equal(t, "30", resp.Header().Get("Retry-After"))
+}
+
+func testDeadLetters(t *testing.T, deps *testDeps) {
+	admin := map[string]string{"X-Admin-Token": testAdminToken}
+	dl := &dispatchers.DeadLetter{Repo: "test", Message: "test message", Error: "non-200 response: 500"}
+	assert.Equal(t, nil, deps.Deps.deadLetters.Save(dl))
+
+	t.Run("TestDeadLetters", func(t *testing.T) {
+		t.Run("Unauthorized", func(t *testing.T) {
+			resp := performRequest(deps.Router, "GET", "/v1/admin/dead_letters", map[string]string{"X-Admin-Token": "nope"}, nil)
+			assert.Equal(t, CodeUnauth, resp.Code, resp.Body.String())
+		})
+		t.Run("List", func(t *testing.T) {
+			resp := performRequest(deps.Router, "GET", "/v1/admin/dead_letters", admin, nil)
+			assert.Equal(t, CodeOK, resp.Code, resp.Body.String())
+
+			var letters []dispatchers.DeadLetter
+			assert.Equal(t, nil, json.Unmarshal(resp.Body.Bytes(), &letters))
+			assert.Equal(t, 1, len(letters))
+			assert.Equal(t, dl.ID, letters[0].ID)
+		})
+		t.Run("RetryMissing", func(t *testing.T) {
+			resp := performRequest(deps.Router, "POST", "/v1/admin/dead_letters/missing/retry", admin, nil)
+			assert.Equal(t, CodeNotFound, resp.Code, resp.Body.String())
+		})
+		t.Run("Retry", func(t *testing.T) {
+			resp := performRequest(deps.Router, "POST", "/v1/admin/dead_letters/"+dl.ID+"/retry", admin, nil)
+			assert.Equal(t, CodeNoContent, resp.Code, resp.Body.String())
+
+			letters, err := deps.Deps.deadLetters.List()
+			assert.Equal(t, nil, err)
+			assert.Equal(t, 0, len(letters))
+		})
+		t.Run("RetryChannel", func(t *testing.T) {
+			var qa *dispatchers.TestDispatcher
+			for _, d := range deps.Deps.dispatchers {
+				if td, ok := d.(*dispatchers.TestDispatcher); ok && td.ChannelName == "qa" {
+					qa = td
+				}
+			}
+			assert.NotEqual(t, (*dispatchers.TestDispatcher)(nil), qa)
+
+			dl := &dispatchers.DeadLetter{Repo: "test", Channel: "qa", Message: "qa message"}
+			assert.Equal(t, nil, deps.Deps.deadLetters.Save(dl))
+			resp := performRequest(deps.Router, "POST", "/v1/admin/dead_letters/"+dl.ID+"/retry", admin, nil)
+			assert.Equal(t, CodeNoContent, resp.Code, resp.Body.String())
+			assert.Equal(t, "qa message", qa.MessageSent)
+		})
+		t.Run("Purge", func(t *testing.T) {
+			assert.Equal(t, nil, deps.Deps.deadLetters.Save(&dispatchers.DeadLetter{Repo: "test", Message: "again"}))
+			resp := performRequest(deps.Router, "DELETE", "/v1/admin/dead_letters", admin, nil)
+			assert.Equal(t, CodeNoContent, resp.Code, resp.Body.String())
+
+			letters, err := deps.Deps.deadLetters.List()
+			assert.Equal(t, nil, err)
+			assert.Equal(t, 0, len(letters))
+		})
+	})
+}
+
+func testDuplicateDelivery(t *testing.T, deps *testDeps) {
+	body := []byte(`{"ref":"test/ref","repository":{"name":"test"},"sender":{"login":"mwebster"}}`)
+	headers := signedHeaders(map[string]string{
+		"X-GitHub-Event":    "push",
+		"X-GitHub-Delivery": "72d3162e-cc78-11e3-81ab-4c9367dc0958",
+		"X-Hub-Signature":   "push",
+		"Content-Type":      "application/json",
+	}, body)
+
+	t.Run("TestDuplicateDelivery", func(t *testing.T) {
+		resp := performRequest(deps.Router, "POST", "/v1/github", headers, body)
+		assert.Equal(t, CodeAccepted, resp.Code, resp.Body.String())
+
+		resp = performRequest(deps.Router, "POST", "/v1/github", headers, body)
+		assert.Equal(t, CodeOK, resp.Code, resp.Body.String())
+		assert.Equal(t, `"{\"message\":\"Delivery already processed\"}"`, resp.Body.String())
+
+		resp = performRequest(deps.Router, "DELETE", "/v1/admin/deliveries/72d3162e-cc78-11e3-81ab-4c9367dc0958", map[string]string{"X-Admin-Token": testAdminToken}, nil)
+		assert.Equal(t, CodeNoContent, resp.Code, resp.Body.String())
+
+		resp = performRequest(deps.Router, "POST", "/v1/github", headers, body)
+		assert.Equal(t, CodeAccepted, resp.Code, resp.Body.String())
+	})
+}
+
+func testEventArchive(t *testing.T, deps *testDeps) {
+	admin := map[string]string{"X-Admin-Token": testAdminToken}
+	body := []byte(`{"action":"opened","issue":{"title":"archived"},"repository":{"name":"test"},"sender":{"login":"archivist"}}`)
+	headers := signedHeaders(map[string]string{
+		"X-GitHub-Event":    "issues",
+		"X-GitHub-Delivery": "archive-test-delivery",
+		"X-Hub-Signature":   "push",
+		"Content-Type":      "application/json",
+	}, body)
+
+	t.Run("TestEventArchive", func(t *testing.T) {
+		resp := performRequest(deps.Router, "POST", "/v1/github", headers, body)
+		assert.Equal(t, CodeAccepted, resp.Code, resp.Body.String())
+
+		resp = performRequest(deps.Router, "GET", "/v1/events?sender=archivist&event=issues&action=opened", admin, nil)
+		assert.Equal(t, CodeOK, resp.Code, resp.Body.String())
+		var found []archive.Delivery
+		assert.Equal(t, nil, json.Unmarshal(resp.Body.Bytes(), &found))
+		assert.Equal(t, 1, len(found))
+		assert.Equal(t, "archive-test-delivery", found[0].ID)
+		assert.Equal(t, "test", found[0].Repo)
+
+		resp = performRequest(deps.Router, "GET", "/v1/events?action=closed&sender=archivist", admin, nil)
+		assert.Equal(t, CodeOK, resp.Code, resp.Body.String())
+		assert.Equal(t, "[]", resp.Body.String())
+
+		resp = performRequest(deps.Router, "GET", "/v1/events?since=yesterday", admin, nil)
+		assert.Equal(t, CodeInvalid, resp.Code, resp.Body.String())
+
+		resp = performRequest(deps.Router, "GET", "/v1/events/archive-test-delivery", admin, nil)
+		assert.Equal(t, CodeOK, resp.Code, resp.Body.String())
+		var d archive.Delivery
+		assert.Equal(t, nil, json.Unmarshal(resp.Body.Bytes(), &d))
+		assert.Equal(t, string(body), string(d.Body))
+
+		resp = performRequest(deps.Router, "GET", "/v1/events/missing", admin, nil)
+		assert.Equal(t, CodeNotFound, resp.Code, resp.Body.String())
+	})
+}
+
+func testReplay(t *testing.T, deps *testDeps) {
+	admin := map[string]string{"X-Admin-Token": testAdminToken, "Content-Type": "application/json"}
+	archived := []struct {
+		Delivery string
+		Event    string
+		Body     string
+	}{
+		{
+			Delivery: "replay-force-push",
+			Event:    "push",
+			Body: `{"ref":"refs/heads/main","forced":true,"before":"1111111111111111111111111111111111111111",` +
+				`"after":"2222222222222222222222222222222222222222","compare":"https://ghe.example.com/mwebster/test/compare/1111111...2222222",` +
+				`"repository":{"name":"test"},"sender":{"login":"mwebster"}}`,
+		},
+		{
+			Delivery: "replay-synchronize",
+			Event:    "pull_request",
+			Body:     `{"action":"synchronize","number":7,"pull_request":{"title":"replayed"},"repository":{"name":"test"},"sender":{"login":"mwebster"}}`,
+		},
+	}
+	cases := []struct {
+		Name         string
+		Body         string
+		ExpectedCode int
+		Check        func(t *testing.T, results []replayResult)
+	}{
+		{
+			Name:         "DryRunByID",
+			Body:         `{"deliveries":["archive-test-delivery","missing"],"mode":"dry_run"}`,
+			ExpectedCode: CodeOK,
+			Check: func(t *testing.T, results []replayResult) {
+				assert.Equal(t, 2, len(results))
+				assert.Equal(t, "issues", results[0].Event)
+				assert.Equal(t, "test", results[0].Repo)
+				assert.Equal(t, true, strings.Contains(results[0].Message, "opened an issue"))
+				assert.Equal(t, errNotFound, results[1].Error)
+			},
+		},
+		{
+			Name:         "ProtectedForcePush",
+			Body:         `{"deliveries":["replay-force-push"],"mode":"dry_run"}`,
+			ExpectedCode: CodeOK,
+			Check: func(t *testing.T, results []replayResult) {
+				assert.Equal(t, 1, len(results))
+				assert.Equal(t, "", results[0].Error)
+				assert.T(t, strings.Contains(results[0].Message, "<!here> :rotating_light: *force-pushed protected ref main*"), results[0].Message)
+			},
+		},
+		{
+			Name:         "Filtered",
+			Body:         `{"deliveries":["replay-synchronize"],"mode":"dry_run"}`,
+			ExpectedCode: CodeOK,
+			Check: func(t *testing.T, results []replayResult) {
+				assert.Equal(t, 1, len(results))
+				assert.Equal(t, true, results[0].Filtered)
+				assert.Equal(t, "", results[0].Message)
+			},
+		},
+		{
+			Name:         "DispatchByRepo",
+			Body:         `{"repo":"test"}`,
+			ExpectedCode: CodeOK,
+			Check: func(t *testing.T, results []replayResult) {
+				assert.NotEqual(t, 0, len(results))
+				for _, r := range results {
+					assert.Equal(t, "", r.Error)
+				}
+			},
+		},
+		{
+			Name:         "NothingPicked",
+			Body:         `{"mode":"dry_run"}`,
+			ExpectedCode: CodeInvalid,
+		},
+		{
+			Name:         "ChannelWithoutURL",
+			Body:         `{"repo":"test","mode":"channel"}`,
+			ExpectedCode: CodeInvalid,
+		},
+	}
+
+	t.Run("TestReplay", func(t *testing.T) {
+		for _, a := range archived {
+			headers := signedHeaders(map[string]string{
+				"X-GitHub-Event":    a.Event,
+				"X-GitHub-Delivery": a.Delivery,
+				"X-Hub-Signature":   "push",
+				"Content-Type":      "application/json",
+			}, []byte(a.Body))
+			resp := performRequest(deps.Router, "POST", "/v1/github", headers, []byte(a.Body))
+			assert.Equal(t, CodeAccepted, resp.Code, resp.Body.String())
+		}
+		for _, c := range cases {
+			t.Run(c.Name, func(t *testing.T) {
+				resp := performRequest(deps.Router, "POST", "/v1/replay", admin, []byte(c.Body))
+				assert.Equal(t, c.ExpectedCode, resp.Code, resp.Body.String())
+				if c.Check != nil {
+					var results []replayResult
+					assert.Equal(t, nil, json.Unmarshal(resp.Body.Bytes(), &results))
+					c.Check(t, results)
+				}
+			})
+		}
+
+		t.Run("OverLimit", func(t *testing.T) {
+			defer func(limit int) { replayLimit = limit }(replayLimit)
+			replayLimit = 2
+
+			resp := performRequest(deps.Router, "POST", "/v1/replay", admin, []byte(`{"repo":"test","mode":"dry_run"}`))
+			assert.Equal(t, CodeInvalid, resp.Code, resp.Body.String())
+			assert.T(t, strings.Contains(resp.Body.String(), "more than 2 deliveries match"), resp.Body.String())
+		})
+	})
+}
+
+func testGitLab(t *testing.T, deps *testDeps) {
+	project := `"project":{"name":"test","web_url":"https://gitlab.example.com/team/test"}`
+	user := `"user":{"name":"Mike Webster","username":"mwebster"}`
+	cases := []struct {
+		Name         string
+		Headers      map[string]string
+		Body         string
+		ExpectedCode int
+		Expected     string
+	}{
+		{
+			Name:         "MissingHeaders",
+			Headers:      map[string]string{},
+			Body:         `{}`,
+			ExpectedCode: CodeInvalid,
+		},
+		{
+			Name:         "BadToken",
+			Headers:      map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "wrong"},
+			Body:         `{"ref":"refs/heads/main",` + project + `}`,
+			ExpectedCode: CodeUnauth,
+		},
+		{
+			Name:         "UnknownProject",
+			Headers:      map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": testSecret},
+			Body:         `{"ref":"refs/heads/main","project":{"name":"not-watched"}}`,
+			ExpectedCode: CodeUnauth,
+		},
+		{
+			Name:         "Push",
+			Headers:      map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": testSecret},
+			Body:         `{"object_kind":"push","ref":"refs/heads/main","before":"95790bf8","after":"da156088","user_name":"Mike Webster","user_username":"mwebster",` + project + `,"commits":[{"id":"da156088","title":"fix the thing"}]}`,
+			ExpectedCode: CodeAccepted,
+			Expected:     "<https://gitlab.example.com/team/test/-/compare/95790bf8...da156088|pushed some changes to refs/heads/main>\n_Commits:_\n```fix the thing```",
+		},
+		{
+			Name:         "TagPush",
+			Headers:      map[string]string{"X-Gitlab-Event": "Tag Push Hook", "X-Gitlab-Token": testSecret},
+			Body:         `{"object_kind":"tag_push","ref":"refs/tags/v1.0.0","user_name":"Mike Webster","user_username":"mwebster",` + project + `}`,
+			ExpectedCode: CodeAccepted,
+			Expected:     "pushed a tag: <https://gitlab.example.com/team/test/-/tags/v1.0.0|v1.0.0>",
+		},
+		{
+			Name:         "MergeRequest",
+			Headers:      map[string]string{"X-Gitlab-Event": "Merge Request Hook", "X-Gitlab-Token": testSecret, "X-Gitlab-Event-UUID": "gitlab-mr-delivery"},
+			Body:         `{"object_kind":"merge_request",` + user + `,` + project + `,"object_attributes":{"iid":1,"title":"test mr","description":"test body","action":"open","url":"https://gitlab.example.com/team/test/-/merge_requests/1"}}`,
+			ExpectedCode: CodeAccepted,
+			Expected:     "*opened a merge request*\n<https://gitlab.example.com/team/test/-/merge_requests/1|Title: test mr>\n```test body```",
+		},
+		{
+			Name:         "MergeRequestMissingAction",
+			Headers:      map[string]string{"X-Gitlab-Event": "Merge Request Hook", "X-Gitlab-Token": testSecret},
+			Body:         `{"object_kind":"merge_request",` + user + `,` + project + `,"object_attributes":{"iid":1}}`,
+			ExpectedCode: CodeInvalid,
+		},
+		{
+			Name:         "Note",
+			Headers:      map[string]string{"X-Gitlab-Event": "Note Hook", "X-Gitlab-Token": testSecret},
+			Body:         `{"object_kind":"note",` + user + `,` + project + `,"object_attributes":{"note":"looks good","noteable_type":"Issue","url":"https://gitlab.example.com/team/test/-/issues/2#note_7"},"issue":{"iid":2,"title":"test issue"}}`,
+			ExpectedCode: CodeAccepted,
+			Expected:     "*commented on an issue*\n<https://gitlab.example.com/team/test/-/issues/2#note_7|Title: test issue>\n```looks good```",
+		},
+		{
+			Name:         "Issue",
+			Headers:      map[string]string{"X-Gitlab-Event": "Issue Hook", "X-Gitlab-Token": testSecret},
+			Body:         `{"object_kind":"issue",` + user + `,` + project + `,"object_attributes":{"iid":2,"title":"test issue","description":"it broke","action":"open","url":"https://gitlab.example.com/team/test/-/issues/2"}}`,
+			ExpectedCode: CodeAccepted,
+			Expected:     "*opened an issue*\n<https://gitlab.example.com/team/test/-/issues/2|Title: test issue>\n```it broke```",
+		},
+		{
+			Name:         "Pipeline",
+			Headers:      map[string]string{"X-Gitlab-Event": "Pipeline Hook", "X-Gitlab-Token": testSecret},
+			Body:         `{"object_kind":"pipeline",` + user + `,` + project + `,"object_attributes":{"id":31,"ref":"main","sha":"bcbb5ec396a2c0f828686f14fac9b80b780504f2","status":"failed","duration":63}}`,
+			ExpectedCode: CodeAccepted,
+			Expected:     "*ran a pipeline that failed*\n<https://gitlab.example.com/team/test/-/pipelines/31|Pipeline #31 on main>\nCommit: `bcbb5ec` -- Duration: 1m3s",
+		},
+		{
+			Name:         "UnknownEvent",
+			Headers:      map[string]string{"X-Gitlab-Event": "Wiki Page Hook", "X-Gitlab-Token": testSecret},
+			Body:         `{"object_kind":"wiki_page",` + user + `,` + project + `}`,
+			ExpectedCode: CodeNoContent,
+		},
+	}
+
+	t.Run("TestGitLab", func(t *testing.T) {
+		for _, c := range cases {
+			t.Run(c.Name, func(t *testing.T) {
+				resp := performRequest(deps.Router, "POST", "/v1/gitlab", c.Headers, []byte(c.Body))
+				assert.Equal(t, c.ExpectedCode, resp.Code, resp.Body.String())
+				if len(c.Expected) < 1 {
+					return
+				}
+
+				event, err := parsePayload(providers[providerGitLab], c.Headers["X-Gitlab-Event"], []byte(c.Body), deps.Deps.logger)
+				assert.Equal(t, nil, err)
+				assert.Equal(t, "test", event.Repository())
+				assert.Equal(t, c.Expected, event.ToString())
+			})
+		}
+
+		t.Run("Replay", func(t *testing.T) {
+			body := []byte(`{"deliveries":["gitlab-mr-delivery"],"mode":"dry_run"}`)
+			resp := performRequest(deps.Router, "POST", "/v1/replay", map[string]string{"X-Admin-Token": testAdminToken}, body)
+			assert.Equal(t, CodeOK, resp.Code, resp.Body.String())
+
+			var results []replayResult
+			assert.Equal(t, nil, json.Unmarshal(resp.Body.Bytes(), &results))
+			assert.Equal(t, 1, len(results))
+			assert.Equal(t, true, strings.HasPrefix(results[0].Message, "Mike Webster *opened a merge request*"), results[0].Message)
+		})
+	})
+}
+
+func testBitbucket(t *testing.T, deps *testDeps) {
+	repo := `{"slug":"test","name":"Test","project":{"key":"TEST"},"links":{"self":[{"href":"https://bitbucket.example.com/projects/TEST/repos/test/browse"}]}}`
+	otherRepo := `{"slug":"test","name":"Test","project":{"key":"OTHER"}}`
+	actor := `"actor":{"name":"mwebster","displayName":"Mike Webster"}`
+	pr := func(r string) string {
+		return `"pullRequest":{"id":1,"title":"test pr","description":"test body","fromRef":{"displayId":"feature"},"toRef":{"displayId":"main","repository":` + r + `},` +
+			`"links":{"self":[{"href":"https://bitbucket.example.com/projects/TEST/repos/test/pull-requests/1"}]}}`
+	}
+	title := "\n<https://bitbucket.example.com/projects/TEST/repos/test/pull-requests/1|Title: test pr>"
+	cases := []struct {
+		Name         string
+		Event        string
+		Body         string
+		Secret       string
+		ExpectedCode int
+		Expected     string
+	}{
+		{
+			Name:         "RefsChanged",
+			Event:        "repo:refs_changed",
+			Body:         `{"eventKey":"repo:refs_changed",` + actor + `,"repository":` + repo + `,"changes":[{"ref":{"displayId":"main","type":"BRANCH"},"fromHash":"aaaaaaaaaa","toHash":"bbbbbbbbbb","type":"UPDATE"}]}`,
+			Secret:       testSecret,
+			ExpectedCode: CodeAccepted,
+			Expected:     "<https://bitbucket.example.com/projects/TEST/repos/test/browse|pushed some changes to main (aaaaaaa..bbbbbbb)>",
+		},
+		{
+			Name:         "Opened",
+			Event:        "pr:opened",
+			Body:         `{"eventKey":"pr:opened",` + actor + `,` + pr(repo) + `}`,
+			Secret:       testSecret,
+			ExpectedCode: CodeAccepted,
+			Expected:     "*opened a pull request*" + title + "\n```test body```",
+		},
+		{
+			Name:         "Merged",
+			Event:        "pr:merged",
+			Body:         `{"eventKey":"pr:merged",` + actor + `,` + pr(repo) + `}`,
+			Secret:       testSecret,
+			ExpectedCode: CodeAccepted,
+			Expected:     "*merged a pull request into main*" + title,
+		},
+		{
+			Name:         "Declined",
+			Event:        "pr:declined",
+			Body:         `{"eventKey":"pr:declined",` + actor + `,` + pr(repo) + `}`,
+			Secret:       testSecret,
+			ExpectedCode: CodeAccepted,
+			Expected:     "*declined a pull request*" + title,
+		},
+		{
+			Name:         "Deleted",
+			Event:        "pr:deleted",
+			Body:         `{"eventKey":"pr:deleted",` + actor + `,` + pr(repo) + `}`,
+			Secret:       testSecret,
+			ExpectedCode: CodeAccepted,
+			Expected:     "*deleted a pull request*" + title,
+		},
+		{
+			Name:         "Approved",
+			Event:        "pr:reviewer:approved",
+			Body:         `{"eventKey":"pr:reviewer:approved",` + actor + `,` + pr(repo) + `}`,
+			Secret:       testSecret,
+			ExpectedCode: CodeAccepted,
+			Expected:     "*approved a pull request*" + title,
+		},
+		{
+			Name:         "CommentAdded",
+			Event:        "pr:comment:added",
+			Body:         `{"eventKey":"pr:comment:added",` + actor + `,` + pr(repo) + `,"comment":{"id":5,"text":"nice"}}`,
+			Secret:       testSecret,
+			ExpectedCode: CodeAccepted,
+			Expected:     "*commented on a pull request*" + title + "\n```nice```",
+		},
+		{
+			Name:         "BadSignature",
+			Event:        "pr:merged",
+			Body:         `{"eventKey":"pr:merged",` + actor + `,` + pr(repo) + `}`,
+			Secret:       "wrong-secret",
+			ExpectedCode: CodeUnauth,
+		},
+		{
+			Name:         "UnknownRepo",
+			Event:        "pr:merged",
+			Body:         `{"eventKey":"pr:merged",` + actor + `,` + pr(otherRepo) + `}`,
+			Secret:       testSecret,
+			ExpectedCode: CodeUnauth,
+		},
+		{
+			Name:         "MissingEventKey",
+			Event:        "pr:merged",
+			Body:         `{` + actor + `,` + pr(repo) + `}`,
+			Secret:       testSecret,
+			ExpectedCode: CodeInvalid,
+		},
+	}
+
+	t.Run("TestBitbucket", func(t *testing.T) {
+		for _, c := range cases {
+			t.Run(c.Name, func(t *testing.T) {
+				body := []byte(c.Body)
+				headers := map[string]string{
+					"X-Event-Key":     c.Event,
+					"X-Hub-Signature": "sha256=" + sign(sha256.New, c.Secret, body),
+				}
+				resp := performRequest(deps.Router, "POST", "/v1/bitbucket", headers, body)
+				assert.Equal(t, c.ExpectedCode, resp.Code, resp.Body.String())
+				if len(c.Expected) < 1 {
+					return
+				}
+
+				event, err := parsePayload(providers[providerBitbucket], c.Event, body, deps.Deps.logger)
+				assert.Equal(t, nil, err)
+				assert.Equal(t, "TEST/test", event.Repository())
+				assert.Equal(t, c.Expected, event.ToString())
+			})
+		}
+	})
+}
+
+func testGitea(t *testing.T, deps *testDeps) {
+	repo := `"repository":{"id":1,"name":"test","full_name":"mwebster/test","html_url":"https://gitea.example.com/mwebster/test"}`
+	sender := `"sender":{"id":1,"login":"mwebster","username":"mwebster","full_name":"Mike Webster"}`
+	cases := []struct {
+		Name         string
+		Event        string
+		Body         string
+		Secret       string
+		ExpectedCode int
+	}{
+		{
+			Name:         "Push",
+			Event:        "push",
+			Body:         `{"ref":"refs/heads/main","compare_url":"https://gitea.example.com/mwebster/test/compare/a...b","commits":[{"id":"b","message":"test commit"}],` + repo + `,` + sender + `}`,
+			Secret:       testSecret,
+			ExpectedCode: CodeAccepted,
+		},
+		{
+			Name:         "PullRequest",
+			Event:        "pull_request",
+			Body:         `{"action":"opened","number":1,"pull_request":{"title":"test pr","body":"test body","html_url":"https://gitea.example.com/mwebster/test/pulls/1"},` + repo + `,` + sender + `}`,
+			Secret:       testSecret,
+			ExpectedCode: CodeAccepted,
+		},
+		{
+			Name:         "Release",
+			Event:        "release",
+			Body:         `{"action":"published","release":{"tag_name":"v1.0.0","name":"v1.0.0","html_url":"https://gitea.example.com/mwebster/test/releases/tag/v1.0.0"},` + repo + `,` + sender + `}`,
+			Secret:       testSecret,
+			ExpectedCode: CodeAccepted,
+		},
+		{
+			Name:         "UnknownEvent",
+			Event:        "wiki",
+			Body:         `{"action":"created",` + repo + `,` + sender + `}`,
+			Secret:       testSecret,
+			ExpectedCode: CodeNoContent,
+		},
+		{
+			Name:         "BadSignature",
+			Event:        "push",
+			Body:         `{"ref":"refs/heads/main",` + repo + `,` + sender + `}`,
+			Secret:       "wrong-secret",
+			ExpectedCode: CodeUnauth,
+		},
+	}
+
+	t.Run("TestGitea", func(t *testing.T) {
+		for _, c := range cases {
+			t.Run(c.Name, func(t *testing.T) {
+				body := []byte(c.Body)
+				headers := map[string]string{
+					"X-Gitea-Event":     c.Event,
+					"X-Gitea-Signature": sign(sha256.New, c.Secret, body),
+				}
+				resp := performRequest(deps.Router, "POST", "/v1/gitea", headers, body)
+				assert.Equal(t, c.ExpectedCode, resp.Code, resp.Body.String())
+			})
+		}
+
+		t.Run("Message", func(t *testing.T) {
+			event, err := parsePayload(providers[providerGitea], "push", []byte(cases[0].Body), deps.Deps.logger)
+			assert.Equal(t, nil, err)
+
+			message, err := eventMessage(context.Background(), "push", event, deps.Deps.logger)
+			assert.Equal(t, nil, err)
+			assert.T(t, strings.HasPrefix(message, "Mike Webster <https://gitea.example.com/mwebster/test/compare/a...b|"), message)
+		})
+	})
+}
+
 func testParseEvent(t *testing.T, deps *testDeps) {
 	cases := []struct {
 		Name        string
@@ -156,7 +836,7 @@ func testParseEvent(t *testing.T, deps *testDeps) {
 				},
 			},
 			DisplayName: "Mike Webster",
-			Code:        CodeNoContent,
+			Code:        CodeAccepted,
 			Headers:     map[string]string{"X-GitHub-Event": "create", "X-Hub-Signature": "push", "Content-Type": "application/json"},
 		},
 		{
@@ -179,7 +859,7 @@ func testParseEvent(t *testing.T, deps *testDeps) {
 				},
 			},
 			DisplayName: "Mike Webster",
-			Code:        CodeNoContent,
+			Code:        CodeAccepted,
 			Headers:     map[string]string{"X-GitHub-Event": "gollum", "X-Hub-Signature": "push", "Content-Type": "application/json"},
 		},
 		{
@@ -199,7 +879,7 @@ func testParseEvent(t *testing.T, deps *testDeps) {
 				},
 			},
 			DisplayName: "Mike Webster",
-			Code:        CodeNoContent,
+			Code:        CodeAccepted,
 			Headers:     map[string]string{"X-GitHub-Event": "issue_comment", "X-Hub-Signature": "push", "Content-Type": "application/json"},
 		},
 		{
@@ -219,7 +899,7 @@ func testParseEvent(t *testing.T, deps *testDeps) {
 				},
 			},
 			DisplayName: "Mike Webster",
-			Code:        CodeNoContent,
+			Code:        CodeAccepted,
 			Headers:     map[string]string{"X-GitHub-Event": "issues", "X-Hub-Signature": "push", "Content-Type": "application/json"},
 		},
 		{
@@ -238,7 +918,7 @@ func testParseEvent(t *testing.T, deps *testDeps) {
 				},
 			},
 			DisplayName: "Mike Webster",
-			Code:        CodeNoContent,
+			Code:        CodeAccepted,
 			Headers:     map[string]string{"X-GitHub-Event": "project_card", "X-Hub-Signature": "push", "Content-Type": "application/json"},
 		},
 		{
@@ -257,7 +937,7 @@ func testParseEvent(t *testing.T, deps *testDeps) {
 				},
 			},
 			DisplayName: "Mike Webster",
-			Code:        CodeNoContent,
+			Code:        CodeAccepted,
 			Headers:     map[string]string{"X-GitHub-Event": "project_column", "X-Hub-Signature": "push", "Content-Type": "application/json"},
 		},
 		{
@@ -277,7 +957,7 @@ func testParseEvent(t *testing.T, deps *testDeps) {
 				},
 			},
 			DisplayName: "Mike Webster",
-			Code:        CodeNoContent,
+			Code:        CodeAccepted,
 			Headers:     map[string]string{"X-GitHub-Event": "pull_request", "X-Hub-Signature": "push", "Content-Type": "application/json"},
 		},
 		{
@@ -300,7 +980,7 @@ func testParseEvent(t *testing.T, deps *testDeps) {
 				},
 			},
 			DisplayName: "Mike Webster",
-			Code:        CodeNoContent,
+			Code:        CodeAccepted,
 			Headers:     map[string]string{"X-GitHub-Event": "pull_request_review_comment", "X-Hub-Signature": "push", "Content-Type": "application/json"},
 		},
 		{
@@ -320,20 +1000,42 @@ func testParseEvent(t *testing.T, deps *testDeps) {
 				},
 			},
 			DisplayName: "Mike Webster",
-			Code:        CodeNoContent,
+			Code:        CodeAccepted,
 			Headers:     map[string]string{"X-GitHub-Event": "pull_request_review", "X-Hub-Signature": "push", "Content-Type": "application/json"},
 		},
+		{
+			Name:      "Release Event",
+			EventName: "release",
+			Body: &webhookmodels.ReleaseEventPayload{
+				Action: "published",
+				Release: webhookmodels.Release{
+					TagName: "v1.0.0",
+					Body:    "## Notes\n* **big** change",
+				},
+				Sender: webhookmodels.User{
+					Login: "mwebster",
+				},
+				Repo: webhookmodels.Repository{
+					Name: "test",
+				},
+			},
+			DisplayName: "Mike Webster",
+			Code:        CodeAccepted,
+			Headers:     map[string]string{"X-GitHub-Event": "release", "X-Hub-Signature": "push", "Content-Type": "application/json"},
+		},
 		{
 			Name:      "Push Event",
 			EventName: "push",
 			Body: &webhookmodels.PushEventPayload{
 				Ref: "webby/test/ref",
-				Commits: []interface{}{
-					map[string]interface{}{
-						"messge": "test commit 1",
+				Commits: []webhookmodels.Commit{
+					webhookmodels.Commit{
+						ID:      "1111111111111111111111111111111111111111",
+						Message: "test commit 1",
 					},
-					map[string]interface{}{
-						"message": "test commit 2",
+					webhookmodels.Commit{
+						ID:      "2222222222222222222222222222222222222222",
+						Message: "test commit 2",
 					},
 				},
 				Sender: webhookmodels.User{
@@ -344,7 +1046,7 @@ func testParseEvent(t *testing.T, deps *testDeps) {
 				},
 			},
 			DisplayName: "Mike Webster",
-			Code:        CodeNoContent,
+			Code:        CodeAccepted,
 			Headers:     map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature": "push", "Content-Type": "application/json"},
 		},
 	}
@@ -358,7 +1060,7 @@ func testParseEvent(t *testing.T, deps *testDeps) {
 					if err != nil {
 						t.Error(err)
 					}
-					resp = performRequest(deps.Router, "POST", "/v1/github", c.Headers, b)
+					resp = performRequest(deps.Router, "POST", "/v1/github", signedHeaders(c.Headers, b), b)
 				} else {
 					resp = performRequest(deps.Router, "POST", "/v1/github", c.Headers, nil)
 				}
@@ -368,6 +1070,588 @@ func testParseEvent(t *testing.T, deps *testDeps) {
 	})
 }
 
+func testReleaseChangelog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/mwebster/test/releases":
+			fmt.Fprint(w, `[{"tag_name":"v1.1.0"},{"tag_name":"v1.0.1","draft":true},{"tag_name":"v1.0.0"}]`)
+		case "/repos/mwebster/test/compare/v1.0.0...v1.1.0":
+			fmt.Fprint(w, `{"commits":[
+				{"commit":{"message":"Merge pull request #4 from mwebster/feature\n\nAdd the feature"}},
+				{"commit":{"message":"fix a typo"}},
+				{"commit":{"message":"Squash the bug (#5)\n\n* details"}}
+			]}`)
+		default:
+			w.WriteHeader(CodeNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("TestReleaseChangelog", func(t *testing.T) {
+		entries, err := releaseChangelog(server.URL, "token", "mwebster/test", "v1.1.0")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []webhookmodels.ChangelogEntry{
+			{Number: 4, Title: "Add the feature"},
+			{Number: 5, Title: "Squash the bug"},
+		}, entries)
+
+		release := &webhookmodels.ReleaseEventPayload{
+			Action:    "published",
+			Release:   webhookmodels.Release{TagName: "v1.1.0", Prerelease: true, URL: "https://ghe.example.com/mwebster/test/releases/tag/v1.1.0"},
+			Repo:      webhookmodels.Repository{URL: "https://ghe.example.com/mwebster/test"},
+			Changelog: entries,
+		}
+		expected := "*published a release*\n<https://ghe.example.com/mwebster/test/releases/tag/v1.1.0|v1.1.0> `v1.1.0` _prerelease_\n_Changes:_\n" +
+			"- <https://ghe.example.com/mwebster/test/pull/4|#4> Add the feature\n" +
+			"- <https://ghe.example.com/mwebster/test/pull/5|#5> Squash the bug"
+		assert.Equal(t, expected, release.ToString())
+
+		release.Release.URL = ""
+		assert.T(t, strings.HasPrefix(release.ToString(), "*published a release*\n*v1.1.0* `v1.1.0` _prerelease_\n"), release.ToString())
+
+		_, err = releaseChangelog(server.URL, "token", "mwebster/missing", "v1.1.0")
+		assert.NotEqual(t, nil, err)
+	})
+}
+
+func testCIPolicy(t *testing.T, deps *testDeps) {
+	status := func(state string, branch string) *webhookmodels.StatusEventPayload {
+		e := &webhookmodels.StatusEventPayload{
+			SHA:       "0123456789abcdef",
+			State:     state,
+			Context:   "ci/build",
+			TargetURL: "https://ci.example.com/build/1",
+			Repo:      webhookmodels.Repository{Name: "test", FullName: "mwebster/test", DefaultBranch: "main"},
+		}
+		e.Branches = append(e.Branches, struct {
+			Name string `json:"name"`
+		}{Name: branch})
+		return e
+	}
+
+	t.Run("TestCIPolicy", func(t *testing.T) {
+		t.Run("Webhook", func(t *testing.T) {
+			b, _ := json.Marshal(status("failure", "main"))
+			headers := map[string]string{"X-GitHub-Event": "status", "X-Hub-Signature": "push"}
+			resp := performRequest(deps.Router, "POST", "/v1/github", signedHeaders(headers, b), b)
+			assert.Equal(t, CodeAccepted, resp.Code, resp.Body.String())
+		})
+
+		t.Run("Message", func(t *testing.T) {
+			expected := "*ci/build failed*\nCommit: `0123456` on main\n<https://ci.example.com/build/1|Details>"
+			assert.Equal(t, expected, status("error", "main").ToString())
+			assert.Equal(t, "", status("pending", "main").ToString())
+		})
+
+		t.Run("FailuresOnly", func(t *testing.T) {
+			policy := &env.CIPolicy{FailuresOnly: true}
+			assert.Equal(t, true, allowCI(deps.Deps, policy, status("failure", "feature"), deps.Deps.logger))
+			assert.Equal(t, false, allowCI(deps.Deps, policy, status("success", "feature"), deps.Deps.logger))
+			assert.Equal(t, false, allowCI(deps.Deps, policy, status("pending", "feature"), deps.Deps.logger))
+		})
+
+		t.Run("DefaultBranchOnly", func(t *testing.T) {
+			policy := &env.CIPolicy{DefaultBranchOnly: true}
+			assert.Equal(t, true, allowCI(deps.Deps, policy, status("failure", "main"), deps.Deps.logger))
+			assert.Equal(t, false, allowCI(deps.Deps, policy, status("failure", "feature"), deps.Deps.logger))
+		})
+
+		t.Run("NotifyRecovery", func(t *testing.T) {
+			policy := &env.CIPolicy{FailuresOnly: true, NotifyRecovery: true}
+			assert.Equal(t, true, allowCI(deps.Deps, policy, status("failure", "recovery"), deps.Deps.logger))
+
+			green := status("success", "recovery")
+			assert.Equal(t, true, allowCI(deps.Deps, policy, green, deps.Deps.logger))
+			assert.NotEqual(t, (*webhookmodels.CIRecovery)(nil), green.Recovery)
+			assert.T(t, strings.HasPrefix(green.ToString(), "*ci/build is green again*"), green.ToString())
+
+			// already green, so it's just a pass now
+			assert.Equal(t, false, allowCI(deps.Deps, policy, status("success", "recovery"), deps.Deps.logger))
+		})
+
+		t.Run("Prune", func(t *testing.T) {
+			store := deps.Deps.ciStates
+			assert.Equal(t, nil, deps.Deps.store.Put(ciStateBucket, "stale", ciFailure{FailedAt: time.Now().Add(-ciStateTTL - time.Hour)}))
+			assert.Equal(t, true, allowCI(deps.Deps, &env.CIPolicy{NotifyRecovery: true}, status("failure", "fresh"), deps.Deps.logger))
+
+			removed, err := store.Prune()
+			assert.Equal(t, nil, err)
+			assert.Equal(t, 1, removed)
+
+			fresh := status("success", "fresh")
+			allowCI(deps.Deps, &env.CIPolicy{NotifyRecovery: true}, fresh, deps.Deps.logger)
+			assert.NotEqual(t, (*webhookmodels.CIRecovery)(nil), fresh.Recovery)
+		})
+
+		t.Run("PullRequestAuthor", func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/repos/mwebster/test/pulls/3":
+					fmt.Fprint(w, `{"number":3,"user":{"login":"author"}}`)
+				case "/repos/mwebster/test/commits/0123456789abcdef/pulls":
+					fmt.Fprint(w, `[{"number":4,"user":{"login":"committer"}}]`)
+				default:
+					w.WriteHeader(CodeNotFound)
+				}
+			}))
+			defer server.Close()
+
+			result := status("success", "feature").CIResult()
+			login, err := pullRequestAuthor(server.URL, "token", &result, []int{3})
+			assert.Equal(t, nil, err)
+			assert.Equal(t, "author", login)
+
+			login, err = pullRequestAuthor(server.URL, "token", &result, nil)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, "committer", login)
+		})
+	})
+}
+
+func testWorkflows(t *testing.T, deps *testDeps) {
+	started := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+	run := func(name string, conclusion string, took time.Duration) *webhookmodels.WorkflowRunEventPayload {
+		return &webhookmodels.WorkflowRunEventPayload{
+			Action: "completed",
+			WorkflowRun: webhookmodels.WorkflowRun{
+				Name:            name,
+				RunNumber:       42,
+				Status:          "completed",
+				Conclusion:      conclusion,
+				URL:             "https://ghe.example.com/mwebster/test/actions/runs/1",
+				HeadBranch:      "main",
+				RunStartedAt:    started,
+				UpdatedAt:       started.Add(took),
+				TriggeringActor: webhookmodels.User{Login: "mwebster"},
+			},
+			Repo: webhookmodels.Repository{Name: "test"},
+		}
+	}
+
+	t.Run("TestWorkflows", func(t *testing.T) {
+		t.Run("Webhook", func(t *testing.T) {
+			for _, event := range []string{"workflow_run", "workflow_job"} {
+				b := []byte(`{"action":"completed","workflow_job":{"name":"build","workflow_name":"CI","run_id":7,"conclusion":"failure"},"workflow_run":{"name":"CI","conclusion":"failure"},"repository":{"name":"test"}}`)
+				headers := map[string]string{"X-GitHub-Event": event, "X-Hub-Signature": "push"}
+				resp := performRequest(deps.Router, "POST", "/v1/github", signedHeaders(headers, b), b)
+				assert.Equal(t, CodeAccepted, resp.Code, resp.Body.String())
+			}
+		})
+
+		t.Run("Message", func(t *testing.T) {
+			expected := "*CI #42: failure*\nBranch: `main`\nTriggered by: mwebster\nTook: 5m3s\n" +
+				"<https://ghe.example.com/mwebster/test/actions/runs/1|View on GitHub>"
+			assert.Equal(t, expected, run("CI", "failure", 5*time.Minute+3*time.Second).ToString())
+
+			inProgress := run("CI", "", 0)
+			inProgress.Action = "in_progress"
+			assert.Equal(t, "", inProgress.ToString())
+		})
+
+		t.Run("Policy", func(t *testing.T) {
+			policy := &env.WorkflowPolicy{Names: []string{"ci"}, Conclusions: []string{"failure"}, SlowMinutes: 30}
+			assert.Equal(t, true, allowWorkflow(policy, run("CI", "failure", time.Minute)))
+			assert.Equal(t, false, allowWorkflow(policy, run("CI", "success", time.Minute)))
+			assert.Equal(t, true, allowWorkflow(policy, run("CI", "success", time.Hour)))
+			assert.Equal(t, false, allowWorkflow(policy, run("Lint", "failure", time.Minute)))
+			assert.Equal(t, true, allowWorkflow(&env.WorkflowPolicy{}, run("Lint", "success", time.Minute)))
+		})
+	})
+}
+
+func testDeployments(t *testing.T, deps *testDeps) {
+	status := func(state string) *webhookmodels.DeploymentStatusEventPayload {
+		e := &webhookmodels.DeploymentStatusEventPayload{
+			Deployment: webhookmodels.Deployment{
+				ID:          12,
+				SHA:         "0123456789abcdef",
+				Ref:         "main",
+				Environment: "production",
+				Creator:     webhookmodels.User{Login: "deploybot"},
+			},
+			Repo: webhookmodels.Repository{Name: "test", FullName: "mwebster/test"},
+		}
+		e.DeploymentStatus.State = state
+		e.DeploymentStatus.LogURL = "https://deploy.example.com/12"
+		return e
+	}
+
+	t.Run("TestDeployments", func(t *testing.T) {
+		t.Run("Webhook", func(t *testing.T) {
+			cases := map[string]string{
+				"deployment":        `{"action":"created","deployment":{"id":11,"ref":"main","environment":"staging"},"repository":{"name":"test"}}`,
+				"deployment_status": `{"action":"created","deployment_status":{"state":"pending"},"deployment":{"id":11},"repository":{"name":"test"}}`,
+			}
+			for event, body := range cases {
+				headers := map[string]string{"X-GitHub-Event": event, "X-Hub-Signature": "push"}
+				resp := performRequest(deps.Router, "POST", "/v1/github", signedHeaders(headers, []byte(body)), []byte(body))
+				assert.Equal(t, CodeAccepted, resp.Code, resp.Body.String())
+			}
+		})
+
+		t.Run("StateChanges", func(t *testing.T) {
+			assert.Equal(t, true, allowDeploymentStatus(deps.Deps, status("pending"), deps.Deps.logger))
+			assert.Equal(t, true, allowDeploymentStatus(deps.Deps, status("in_progress"), deps.Deps.logger))
+			assert.Equal(t, false, allowDeploymentStatus(deps.Deps, status("in_progress"), deps.Deps.logger))
+
+			done := status("success")
+			assert.Equal(t, true, allowDeploymentStatus(deps.Deps, done, deps.Deps.logger))
+			expected := "*deployment #12 to production: success*\nRef: `main` (`0123456`)\nCreated by: deploybot\n" +
+				"History: pending → in_progress → success\n<https://deploy.example.com/12|Logs>"
+			assert.Equal(t, expected, done.ToString())
+		})
+	})
+}
+
+func testProtectedRefs(t *testing.T, deps *testDeps) {
+	push := func(ref string, forced bool, deleted bool) *webhookmodels.PushEventPayload {
+		return &webhookmodels.PushEventPayload{
+			Ref:     ref,
+			Before:  "1111111111111111111111111111111111111111",
+			After:   "2222222222222222222222222222222222222222",
+			Forced:  forced,
+			Deleted: deleted,
+			URL:     "https://ghe.example.com/mwebster/test/compare/1111111...2222222",
+			Repo:    webhookmodels.Repository{Name: "test"},
+		}
+	}
+
+	t.Run("TestProtectedRefs", func(t *testing.T) {
+		t.Run("Webhook", func(t *testing.T) {
+			b := []byte(`{"ref":"feature","ref_type":"branch","repository":{"name":"test"}}`)
+			headers := map[string]string{"X-GitHub-Event": "delete", "X-Hub-Signature": "push"}
+			resp := performRequest(deps.Router, "POST", "/v1/github", signedHeaders(headers, b), b)
+			assert.Equal(t, CodeAccepted, resp.Code, resp.Body.String())
+		})
+
+		t.Run("ForcePush", func(t *testing.T) {
+			e := push("refs/heads/release/1.0", true, false)
+			enrichEvent(e, deps.Deps.logger)
+			expected := "<!here> :rotating_light: *force-pushed protected ref release/1.0*\n" +
+				"Before: `1111111111111111111111111111111111111111`\nAfter: `2222222222222222222222222222222222222222`\n" +
+				"<https://ghe.example.com/mwebster/test/compare/1111111...2222222|Compare>"
+			assert.Equal(t, expected, e.ToString())
+
+			unprotected := push("refs/heads/feature", true, false)
+			enrichEvent(unprotected, deps.Deps.logger)
+			assert.T(t, strings.HasPrefix(unprotected.ToString(), "<https://ghe.example.com/mwebster/test/compare/1111111...2222222|force-pushed some changes"))
+		})
+
+		t.Run("Deleted", func(t *testing.T) {
+			e := push("refs/heads/main", false, true)
+			enrichEvent(e, deps.Deps.logger)
+			assert.T(t, strings.HasPrefix(e.ToString(), "<!here> :rotating_light: *deleted protected ref main*"))
+
+			// the delete event is left to announce unprotected deletions
+			assert.Equal(t, "", push("refs/heads/feature", false, true).ToString())
+
+			del := &webhookmodels.DeleteEventPayload{Type: "branch", Ref: "main", Repo: webhookmodels.Repository{Name: "test"}}
+			enrichEvent(del, deps.Deps.logger)
+			assert.Equal(t, "", del.ToString())
+
+			del = &webhookmodels.DeleteEventPayload{Type: "branch", Ref: "feature", Repo: webhookmodels.Repository{Name: "test"}}
+			enrichEvent(del, deps.Deps.logger)
+			assert.Equal(t, "deleted a branch: `feature`", del.ToString())
+		})
+	})
+}
+
+func testPushCommits(t *testing.T) {
+	b := []byte(`{
+		"ref": "refs/heads/main",
+		"compare": "https://ghe.example.com/mwebster/test/compare/a...b",
+		"sender": {"login": "mwebster"},
+		"pusher": {"name": "Mike Webster", "email": "mike@example.com"},
+		"commits": [
+			{"id": "1111111111", "message": "first change\n\nwith a body", "url": "https://ghe.example.com/c/1", "distinct": true,
+			 "author": {"name": "Mike Webster", "email": "mike@example.com", "username": "mwebster"}, "added": ["a.go"], "modified": ["b.go"]},
+			{"id": "2222222222", "message": "second change", "url": "https://ghe.example.com/c/2",
+			 "author": {"name": "Pat Smith", "email": "pat@example.com", "username": "psmith"}},
+			{"id": "3333333333", "message": "third change", "url": "https://ghe.example.com/c/3"}
+		]
+	}`)
+	event := &webhookmodels.PushEventPayload{}
+
+	t.Run("TestPushCommits", func(t *testing.T) {
+		assert.Equal(t, nil, json.Unmarshal(b, event))
+		assert.Equal(t, []string{"a.go"}, event.Commits[0].Added)
+		assert.Equal(t, []string{"b.go"}, event.Commits[0].Modified)
+		assert.Equal(t, true, event.Commits[0].Distinct)
+
+		event.CommitLimit = 2
+		expected := "<https://ghe.example.com/mwebster/test/compare/a...b|pushed some changes to refs/heads/main>\n_Commits:_\n" +
+			"<https://ghe.example.com/c/1|`1111111`> first change\n" +
+			"<https://ghe.example.com/c/2|`2222222`> second change - Pat Smith\n" +
+			"_and 1 more_"
+		assert.Equal(t, expected, event.ToString())
+
+		t.Run("LargePush", func(t *testing.T) {
+			large := &webhookmodels.PushEventPayload{
+				Ref:         "refs/heads/main",
+				URL:         "https://ghe.example.com/mwebster/test/compare/a...b",
+				CommitLimit: 10,
+			}
+			for i := 0; i < webhookmodels.PayloadCommitCap; i++ {
+				large.Commits = append(large.Commits, webhookmodels.Commit{ID: fmt.Sprintf("%040d", i), Message: "change"})
+			}
+			unknown := "<https://ghe.example.com/mwebster/test/compare/a...b|and more — see compare>"
+			assert.T(t, strings.HasSuffix(large.ToString(), "\n_"+unknown+"_"), large.ToString())
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/repos/mwebster/test/compare/a...b", r.URL.Path)
+				w.Write([]byte(`{"total_commits":57,"commits":[]}`))
+			}))
+			defer server.Close()
+			size, err := compareSize(server.URL, "token", "mwebster/test", "a", "b")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, 57, size)
+
+			large.Size = size
+			assert.T(t, strings.HasSuffix(large.ToString(), "change\n_and 47 more_"), large.ToString())
+			large.CommitLimit = 30
+			assert.T(t, strings.HasSuffix(large.ToString(), "change\n_and 37 more_"), large.ToString())
+		})
+	})
+}
+
+func testPullRequestActions(t *testing.T, deps *testDeps) {
+	pr := func(action string) *webhookmodels.PullRequestEventPayload {
+		e := &webhookmodels.PullRequestEventPayload{
+			Action: action,
+			PullRequest: webhookmodels.PullRequest{
+				URL:   "https://ghe.example.com/mwebster/test/pull/1",
+				Title: "test pr",
+			},
+			Repo: webhookmodels.Repository{Name: "test", URL: "https://ghe.example.com/mwebster/test"},
+		}
+		e.PullRequest.Base.Branch = "main"
+		return e
+	}
+	title := "\n<https://ghe.example.com/mwebster/test/pull/1|Title: test pr>"
+
+	t.Run("TestPullRequestActions", func(t *testing.T) {
+		merged := pr("closed")
+		merged.PullRequest.Merged = true
+		merged.PullRequest.MergeCommitSHA = "abcdef0123456789"
+		assert.Equal(t, "*merged a pull request into main*"+title+
+			"\nMerge commit: <https://ghe.example.com/mwebster/test/commit/abcdef0123456789|`abcdef0`>", merged.ToString())
+		assert.Equal(t, "*closed a pull request*"+title, pr("closed").ToString())
+
+		requested := pr("review_requested")
+		requested.RequestedReviewer.Login = "psmith"
+		assert.Equal(t, "*requested a review from psmith*"+title, requested.ToString())
+		removed := pr("review_request_removed")
+		removed.RequestedTeam.Name = "core"
+		assert.Equal(t, "*removed the review request for the core team*"+title, removed.ToString())
+
+		assigned := pr("assigned")
+		assigned.Assignee.Login = "psmith"
+		assert.Equal(t, "*assigned psmith to a pull request*"+title, assigned.ToString())
+
+		synced := pr("synchronize")
+		synced.Before, synced.After = "aaa", "bbb"
+		assert.Equal(t, "*pushed new commits to a pull request*"+title+
+			"\n<https://ghe.example.com/mwebster/test/compare/aaa...bbb|Compare>", synced.ToString())
+
+		assert.Equal(t, "*reopened a pull request*"+title, pr("reopened").ToString())
+		assert.Equal(t, "*marked a pull request ready for review*"+title, pr("ready_for_review").ToString())
+		assert.Equal(t, "*converted a pull request to a draft*"+title, pr("converted_to_draft").ToString())
+		assert.Equal(t, "", pr("locked").ToString())
+
+		t.Run("OptIn", func(t *testing.T) {
+			assert.Equal(t, true, allowEvent(deps.Deps, pr("opened"), deps.Deps.logger))
+			assert.Equal(t, false, allowEvent(deps.Deps, pr("synchronize"), deps.Deps.logger))
+
+			w := &env.Watcher{PullRequestActions: []string{"synchronize"}}
+			assert.Equal(t, true, w.AllowsPullRequestAction("synchronize"))
+			assert.Equal(t, false, w.AllowsPullRequestAction("opened"))
+		})
+
+		t.Run("DeployMaster", func(t *testing.T) {
+			closed := pr("closed")
+			closed.Repo.Name = "academy"
+			var event webhookmodels.Event = closed
+			_, deploy := webhookmodels.ShouldDeployMaster(&event)
+			assert.Equal(t, false, deploy)
+
+			closed.PullRequest.Merged = true
+			_, deploy = webhookmodels.ShouldDeployMaster(&event)
+			assert.Equal(t, true, deploy)
+
+			event = pr("opened")
+			_, deploy = webhookmodels.ShouldDeployMaster(&event)
+			assert.Equal(t, false, deploy)
+		})
+	})
+}
+
+func testEdits(t *testing.T, deps *testDeps) {
+	issue := func(oldTitle string, oldBody string) *webhookmodels.IssuesEventPayload {
+		e := &webhookmodels.IssuesEventPayload{
+			Action: "edited",
+			Issue: webhookmodels.Issue{
+				URL:   "https://ghe.example.com/mwebster/test/issues/1",
+				Title: "new title",
+				Body:  "intro\n- [x] first\n- [ ] second\nlast line",
+			},
+		}
+		if len(oldTitle) > 0 {
+			e.Changes.Title = &webhookmodels.ChangedValue{From: oldTitle}
+		}
+		if len(oldBody) > 0 {
+			e.Changes.Body = &webhookmodels.ChangedValue{From: oldBody}
+		}
+		return e
+	}
+	header := "*edited an issue*\n<https://ghe.example.com/mwebster/test/issues/1|Title: new title>\n"
+
+	t.Run("TestEdits", func(t *testing.T) {
+		t.Run("Webhook", func(t *testing.T) {
+			b := []byte(`{"action":"edited","issue":{"title":"new"},"changes":{"title":{"from":"old"}},"repository":{"name":"test"}}`)
+			headers := map[string]string{"X-GitHub-Event": "issues", "X-Hub-Signature": "push"}
+			resp := performRequest(deps.Router, "POST", "/v1/github", signedHeaders(headers, b), b)
+			assert.Equal(t, CodeAccepted, resp.Code, resp.Body.String())
+		})
+
+		t.Run("Title", func(t *testing.T) {
+			assert.Equal(t, header+"Title: ~old title~ → new title", issue("old title", "").ToString())
+		})
+
+		t.Run("Body", func(t *testing.T) {
+			e := issue("", "intro\n- [ ] first\n- [ ] second\nremoved line\nlast line")
+			assert.Equal(t, header+"```- removed line```", e.ToString())
+
+			e = issue("", "intro\n- [ ] first\n- [ ] second\nold last line")
+			assert.Equal(t, header+"```- old last line\n+ last line```", e.ToString())
+		})
+
+		t.Run("Suppressed", func(t *testing.T) {
+			// only whitespace and checkboxes changed
+			e := issue("new title ", "  intro  \n\n- [ ] first\n- [x]   second\r\nlast line")
+			assert.Equal(t, "", e.ToString())
+		})
+
+		t.Run("Comment", func(t *testing.T) {
+			e := &webhookmodels.IssueCommentEventPayload{
+				Action:  "edited",
+				Issue:   webhookmodels.Issue{URL: "https://ghe.example.com/mwebster/test/issues/1", Title: "issue"},
+				Comment: webhookmodels.IssueComment{Body: "looks good to me"},
+				Changes: webhookmodels.Changes{Body: &webhookmodels.ChangedValue{From: "looks good"}},
+			}
+			expected := "*edited a comment on an issue*\n<https://ghe.example.com/mwebster/test/issues/1|Title: issue>\n" +
+				"```- looks good\n+ looks good to me```"
+			assert.Equal(t, expected, e.ToString())
+		})
+	})
+}
+
+func testReviewComments(t *testing.T, deps *testDeps) {
+	comment := func(id int64, replyTo int64, login string, body string) *webhookmodels.PullRequestReviewCommentEventPayload {
+		return &webhookmodels.PullRequestReviewCommentEventPayload{
+			Action:      "created",
+			PullRequest: webhookmodels.PullRequest{Number: 1, Title: "test pr", URL: "https://ghe.example.com/mwebster/test/pull/1"},
+			Comment: webhookmodels.ReviewComment{
+				ID:          id,
+				InReplyToID: replyTo,
+				URL:         "https://ghe.example.com/mwebster/test/pull/1#discussion_r1",
+				Path:        "main.go",
+				Line:        12,
+				DiffHunk:    "@@ -1,7 +1,8 @@\n a\n b\n c\n d\n e\n f\n+g",
+				User:        webhookmodels.User{Login: login},
+				Body:        body,
+			},
+			Repo: webhookmodels.Repository{Name: "test", FullName: "mwebster/test"},
+		}
+	}
+	header := "\n<https://ghe.example.com/mwebster/test/pull/1|Title:  test pr>\n" +
+		"<https://ghe.example.com/mwebster/test/pull/1#discussion_r1|`main.go:12`>\n" +
+		"``` c\n d\n e\n f\n+g```\n"
+
+	t.Run("TestReviewComments", func(t *testing.T) {
+		t.Run("Single", func(t *testing.T) {
+			e := comment(1, 0, "mwebster", "why **this**?")
+			assert.Equal(t, "*created a comment on a pull request review*"+header+"> why *this*?", e.ToString())
+		})
+
+		t.Run("Grouped", func(t *testing.T) {
+			announced := make(chan job, 2)
+			rt := newReviewThreads(20*time.Millisecond, nil, deps.Deps.logger, func(j job) { announced <- j })
+
+			first := comment(1, 0, "mwebster", "why this?")
+			assert.Equal(t, true, rt.Hold(job{event: first}))
+			assert.Equal(t, true, rt.Hold(job{event: comment(2, 1, "psmith", "because")}))
+			assert.Equal(t, true, rt.Hold(job{event: comment(3, 1, "mwebster", "ok")}))
+			// a different conversation isn't grouped with it
+			assert.Equal(t, true, rt.Hold(job{event: comment(4, 0, "psmith", "typo")}))
+
+			got := []string{}
+			for i := 0; i < 2; i++ {
+				select {
+				case j := <-announced:
+					got = append(got, j.event.ToString())
+				case <-time.After(time.Second):
+					t.Fatal("review comments weren't announced")
+				}
+			}
+			expected := "*created a comment on a pull request review*" + header +
+				"> *mwebster*: why this?\n> *psmith*: because\n> *mwebster*: ok"
+			assert.T(t, got[0] == expected || got[1] == expected, got)
+
+			edited := comment(1, 0, "mwebster", "why this?")
+			edited.Action = "edited"
+			assert.Equal(t, false, rt.Hold(job{event: edited}))
+		})
+
+		t.Run("Restart", func(t *testing.T) {
+			// a store of its own, so comments the test server is holding don't
+			// turn up
+			dir, err := ioutil.TempDir("", "repo-watcher")
+			assert.Equal(t, nil, err)
+			store, err := storage.Open(filepath.Join(dir, "threads.db"))
+			assert.Equal(t, nil, err)
+			defer store.Close()
+
+			before := newReviewThreads(time.Hour, store, deps.Deps.logger, func(j job) { t.Error("announced before the restart") })
+			assert.Equal(t, true, before.Hold(job{eventName: "pull_request_review_comment", event: comment(1, 0, "mwebster", "why this?")}))
+			assert.Equal(t, true, before.Hold(job{eventName: "pull_request_review_comment", event: comment(2, 1, "psmith", "because")}))
+
+			announced := make(chan job, 1)
+			after := newReviewThreads(time.Millisecond, store, deps.Deps.logger, func(j job) { announced <- j })
+			assert.Equal(t, nil, after.Resume())
+			select {
+			case j := <-announced:
+				assert.Equal(t, "pull_request_review_comment", j.eventName)
+				expected := "*created a comment on a pull request review*" + header +
+					"> *mwebster*: why this?\n> *psmith*: because"
+				assert.Equal(t, expected, j.event.ToString())
+			case <-time.After(time.Second):
+				t.Fatal("held review comments weren't announced after the restart")
+			}
+
+			found, err := store.Get(threadBucket, comment(1, 0, "mwebster", "").ThreadKey(), &heldThread{})
+			assert.Equal(t, nil, err)
+			assert.Equal(t, false, found)
+		})
+	})
+}
+
+// signedHeaders returns a copy of the headers with the X-Hub-Signature-256
+// GitHub would send for the body using the test watcher's secret.
+func signedHeaders(headers map[string]string, body []byte) map[string]string {
+	ret := map[string]string{}
+	for k, v := range headers {
+		ret[k] = v
+	}
+	ret["X-Hub-Signature-256"] = "sha256=" + sign(sha256.New, testSecret, body)
+	return ret
+}
+
+func sign(h func() hash.Hash, secret string, body []byte) string {
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 func performRequest(r http.Handler, method string, path string, headers map[string]string, body []byte) *httptest.ResponseRecorder {
 	var req *http.Request
 	if len(body) > 0 {
@@ -384,3 +1668,418 @@ func performRequest(r http.Handler, method string, path string, headers map[stri
 	r.ServeHTTP(w, req)
 	return w
 }
+
+func testReviews(t *testing.T, deps *testDeps) {
+	review := func(action string, login string, state string, body string) *webhookmodels.PullRequestReviewEventPayload {
+		return &webhookmodels.PullRequestReviewEventPayload{
+			Action:      action,
+			PullRequest: webhookmodels.PullRequest{Number: 7, Title: "test pr", URL: "https://ghe.example.com/mwebster/test/pull/7"},
+			Review: webhookmodels.Review{
+				User:  webhookmodels.User{Login: login},
+				State: state,
+				Body:  body,
+				URL:   "https://ghe.example.com/mwebster/test/pull/7#pullrequestreview-1",
+			},
+			Repo: webhookmodels.Repository{Name: "test", FullName: "mwebster/test"},
+		}
+	}
+	title := "\n<https://ghe.example.com/mwebster/test/pull/7#pullrequestreview-1|Title: test pr>\n"
+
+	t.Run("TestReviews", func(t *testing.T) {
+		t.Run("Rendering", func(t *testing.T) {
+			e := review("submitted", "alice", "changes_requested", "please **rename** this")
+			assert.Equal(t, "*requested changes on a pull request*"+title+"> please *rename* this", e.ToString())
+
+			e = review("submitted", "alice", "commented", "")
+			assert.Equal(t, "", e.ToString())
+
+			e = review("dismissed", "alice", "dismissed", "")
+			assert.Equal(t, "*dismissed alice's review*"+title[:len(title)-1], e.ToString())
+		})
+
+		t.Run("Tally", func(t *testing.T) {
+			logger := deps.Deps.logger
+			steps := []struct {
+				review   *webhookmodels.PullRequestReviewEventPayload
+				expected string
+			}{
+				{review("submitted", "alice", "approved", ""), "1 approval, 0 change requests — ready to merge?"},
+				{review("submitted", "bob", "CHANGES_REQUESTED", "nope"), "1 approval, 1 change request"},
+				{review("submitted", "bob", "commented", "still nope"), "1 approval, 1 change request"},
+				{review("submitted", "carol", "approved", ""), "2 approvals, 1 change request"},
+				{review("dismissed", "bob", "dismissed", ""), "2 approvals, 0 change requests — ready to merge?"},
+			}
+			for _, step := range steps {
+				assert.Equal(t, true, allowEvent(deps.Deps, step.review, logger))
+				assert.Equal(t, step.expected, step.review.Tally.ToString())
+			}
+			assert.Equal(t, true, strings.HasSuffix(steps[0].review.ToString(), "\n_1 approval, 0 change requests — ready to merge?_"))
+
+			closed := &webhookmodels.PullRequestEventPayload{
+				Action:      "closed",
+				PullRequest: webhookmodels.PullRequest{Number: 7},
+				Repo:        webhookmodels.Repository{Name: "test", FullName: "mwebster/test"},
+			}
+			allowEvent(deps.Deps, closed, logger)
+			e := review("submitted", "alice", "approved", "")
+			allowEvent(deps.Deps, e, logger)
+			assert.Equal(t, "1 approval, 0 change requests — ready to merge?", e.Tally.ToString())
+		})
+	})
+}
+
+func testDiscussions(t *testing.T, deps *testDeps) {
+	discussion := func(category string) webhookmodels.Discussion {
+		return webhookmodels.Discussion{
+			Number: 3,
+			URL:    "https://ghe.example.com/mwebster/test/discussions/3",
+			Title:  "how do I deploy?",
+			Body:   "asking for a **friend**",
+			Category: webhookmodels.DiscussionCategory{
+				Name:         category,
+				Emoji:        ":pray:",
+				IsAnswerable: category == "Q&A",
+			},
+		}
+	}
+	repo := webhookmodels.Repository{Name: "test", FullName: "mwebster/test"}
+	title := "Category: :pray: Q&A\n<https://ghe.example.com/mwebster/test/discussions/3|Title: how do I deploy?>\n"
+
+	t.Run("TestDiscussions", func(t *testing.T) {
+		t.Run("Webhook", func(t *testing.T) {
+			cases := map[string]string{
+				"discussion":         `{"action":"created","discussion":{"number":3,"title":"hi","category":{"name":"General"}},"repository":{"name":"test"}}`,
+				"discussion_comment": `{"action":"created","comment":{"body":"hello"},"discussion":{"number":3},"repository":{"name":"test"}}`,
+			}
+			for event, body := range cases {
+				headers := map[string]string{"X-GitHub-Event": event, "X-Hub-Signature": "push"}
+				resp := performRequest(deps.Router, "POST", "/v1/github", signedHeaders(headers, []byte(body)), []byte(body))
+				assert.Equal(t, CodeAccepted, resp.Code, resp.Body.String())
+			}
+		})
+
+		t.Run("Rendering", func(t *testing.T) {
+			e := &webhookmodels.DiscussionEventPayload{Action: "created", Discussion: discussion("Q&A"), Repo: repo}
+			assert.Equal(t, "*started a discussion*\n"+title+"Unanswered\n> asking for a *friend*", e.ToString())
+
+			e.Action = "answered"
+			e.Discussion.AnswerURL = "https://ghe.example.com/mwebster/test/discussions/3#discussioncomment-9"
+			e.Answer = &webhookmodels.DiscussionComment{
+				URL:  e.Discussion.AnswerURL,
+				Body: "run `make deploy`",
+				User: webhookmodels.User{Login: "alice"},
+			}
+			expected := "*marked an answer to a discussion*\n" + title + ":white_check_mark: Answered\n" +
+				"<https://ghe.example.com/mwebster/test/discussions/3#discussioncomment-9|Answer by alice>\n> run `make deploy`"
+			assert.Equal(t, expected, e.ToString())
+
+			c := &webhookmodels.DiscussionCommentEventPayload{
+				Action:     "created",
+				Comment:    webhookmodels.DiscussionComment{ParentID: 9, Body: "thanks!"},
+				Discussion: discussion("General"),
+				Repo:       repo,
+			}
+			expected = "*replied to a comment on a discussion*\nCategory: :pray: General\n" +
+				"<https://ghe.example.com/mwebster/test/discussions/3|Title: how do I deploy?>\n> thanks!"
+			assert.Equal(t, expected, c.ToString())
+		})
+
+		t.Run("Routing", func(t *testing.T) {
+			var qa *dispatchers.TestDispatcher
+			for _, d := range deps.Deps.dispatchers {
+				if td, ok := d.(*dispatchers.TestDispatcher); ok && td.ChannelName == "qa" {
+					qa = td
+				}
+			}
+			assert.NotEqual(t, (*dispatchers.TestDispatcher)(nil), qa)
+
+			general := &webhookmodels.DiscussionEventPayload{Action: "created", Discussion: discussion("General"), Repo: repo}
+			assert.Equal(t, "", eventChannel(general))
+
+			e := &webhookmodels.DiscussionEventPayload{Action: "created", Discussion: discussion("q&a"), Repo: repo}
+			assert.Equal(t, "qa", eventChannel(e))
+			deps.Deps.queue.announce(job{ctx: context.Background(), eventName: "discussion", event: e})
+			assert.T(t, strings.Contains(qa.MessageSent, "started a discussion"), qa.MessageSent)
+		})
+	})
+}
+
+func testSecurityAlerts(t *testing.T, deps *testDeps) {
+	dependabot := `{"action":"dismissed","alert":{"number":12,"state":"dismissed","html_url":"https://ghe.example.com/mwebster/test/security/dependabot/12",` +
+		`"dependency":{"package":{"ecosystem":"npm","name":"lodash"},"manifest_path":"package-lock.json"},` +
+		`"security_advisory":{"cve_id":"CVE-2021-23337","summary":"Command injection in lodash","severity":"high"},` +
+		`"security_vulnerability":{"vulnerable_version_range":"< 4.17.21","first_patched_version":{"identifier":"4.17.21"}},` +
+		`"dismissed_reason":"tolerable_risk","dismissed_comment":"build tooling only"},"repository":{"name":"test"},"sender":{"login":"mwebster"}}`
+	codeScanning := `{"action":"created","ref":"refs/heads/main","alert":{"number":5,"html_url":"https://ghe.example.com/mwebster/test/security/code-scanning/5",` +
+		`"rule":{"id":"go/sql-injection","description":"Database query built from user-controlled sources","severity":"error","security_severity_level":"critical"},` +
+		`"tool":{"name":"CodeQL"},"most_recent_instance":{"location":{"path":"store.go","start_line":42}}},"repository":{"name":"test"}}`
+	secretScanning := `{"action":"created","alert":{"number":2,"html_url":"https://ghe.example.com/mwebster/test/security/secret-scanning/2",` +
+		`"secret_type_display_name":"GitHub Personal Access Token"},"repository":{"name":"test"}}`
+
+	t.Run("TestSecurityAlerts", func(t *testing.T) {
+		t.Run("Webhook", func(t *testing.T) {
+			cases := map[string]string{
+				"dependabot_alert":      dependabot,
+				"code_scanning_alert":   codeScanning,
+				"secret_scanning_alert": secretScanning,
+			}
+			for event, body := range cases {
+				headers := map[string]string{"X-GitHub-Event": event, "X-Hub-Signature": "push"}
+				resp := performRequest(deps.Router, "POST", "/v1/github", signedHeaders(headers, []byte(body)), []byte(body))
+				assert.Equal(t, CodeAccepted, resp.Code, resp.Body.String())
+			}
+		})
+
+		t.Run("Rendering", func(t *testing.T) {
+			event, err := parsePayload(providers[providerGitHub], "dependabot_alert", []byte(dependabot), deps.Deps.logger)
+			assert.Equal(t, nil, err)
+			expected := "*dismissed a dependabot alert*\n" +
+				"<https://ghe.example.com/mwebster/test/security/dependabot/12|#12 Command injection in lodash (CVE-2021-23337)>\n" +
+				"Severity: :large_orange_circle: high\nPackage: `lodash` (npm) in `package-lock.json`\n" +
+				"Vulnerable: `< 4.17.21`, patched in `4.17.21`\nReason: tolerable risk — build tooling only"
+			assert.Equal(t, expected, event.ToString())
+
+			event, err = parsePayload(providers[providerGitHub], "code_scanning_alert", []byte(codeScanning), deps.Deps.logger)
+			assert.Equal(t, nil, err)
+			expected = "*opened a code scanning alert*\n" +
+				"<https://ghe.example.com/mwebster/test/security/code-scanning/5|#5 Database query built from user-controlled sources>\n" +
+				"Severity: :red_circle: critical\nRule: `go/sql-injection` (CodeQL)\nFile: `store.go:42` on `main`"
+			assert.Equal(t, expected, event.ToString())
+		})
+
+		t.Run("Policy", func(t *testing.T) {
+			alert := func(severity string) *webhookmodels.DependabotAlertEventPayload {
+				e := &webhookmodels.DependabotAlertEventPayload{Action: "created", Repo: webhookmodels.Repository{Name: "test"}}
+				e.Alert.SecurityAdvisory.Severity = severity
+				return e
+			}
+			assert.Equal(t, false, allowEvent(deps.Deps, alert("moderate"), deps.Deps.logger))
+			assert.Equal(t, true, allowEvent(deps.Deps, alert("high"), deps.Deps.logger))
+			assert.Equal(t, true, allowEvent(deps.Deps, alert("critical"), deps.Deps.logger))
+			assert.Equal(t, true, allowEvent(deps.Deps, &webhookmodels.SecretScanningAlertEventPayload{Repo: webhookmodels.Repository{Name: "test"}}, deps.Deps.logger))
+			assert.Equal(t, "security", eventChannel(alert("critical")))
+		})
+	})
+}
+
+func testAuditEvents(t *testing.T, deps *testDeps) {
+	repo := `"repository":{"name":"test","full_name":"mwebster/test","html_url":"https://ghe.example.com/mwebster/test"},"sender":{"login":"mwebster"}`
+	member := `{"action":"edited","member":{"login":"alice","html_url":"https://ghe.example.com/alice"},` +
+		`"changes":{"permission":{"from":"write","to":"admin"}},` + repo + `}`
+	repository := `{"action":"publicized",` + repo + `}`
+	protection := `{"action":"edited","rule":{"name":"main","required_approving_review_count":1,"admin_enforced":true,"allow_force_pushes_enforcement_level":"everyone"},` +
+		`"changes":{"required_approving_review_count":{"from":2},"allow_force_pushes_enforcement_level":{"from":"off"}},` + repo + `}`
+	teamAdd := `{"team":{"name":"Platform","html_url":"https://ghe.example.com/orgs/mwebster/teams/platform","permission":"admin"},` + repo + `}`
+
+	t.Run("TestAuditEvents", func(t *testing.T) {
+		t.Run("Webhook", func(t *testing.T) {
+			cases := map[string]string{
+				"member":                 member,
+				"repository":             repository,
+				"branch_protection_rule": protection,
+				"team_add":               teamAdd,
+			}
+			for event, body := range cases {
+				headers := map[string]string{"X-GitHub-Event": event, "X-Hub-Signature": "push"}
+				resp := performRequest(deps.Router, "POST", "/v1/github", signedHeaders(headers, []byte(body)), []byte(body))
+				assert.Equal(t, CodeAccepted, resp.Code, resp.Body.String())
+			}
+		})
+
+		t.Run("Rendering", func(t *testing.T) {
+			cases := map[string]string{
+				member: "*:warning: changed a collaborator's permission*\nRepository: mwebster/test\n" +
+					"<https://ghe.example.com/alice|Collaborator: alice>\nPermission: write → admin",
+				repository: "*:warning: made a repository public*\n<https://ghe.example.com/mwebster/test|Repository: mwebster/test>\n" +
+					"Visibility: private → public",
+				protection: "*:warning: loosened a branch protection rule*\nRepository: mwebster/test\nBranches: `main`\n" +
+					"allow force pushes: off → everyone\nrequired approving review count: 2 → 1",
+			}
+			names := map[string]string{member: "member", repository: "repository", protection: "branch_protection_rule"}
+			for body, expected := range cases {
+				event, err := parsePayload(providers[providerGitHub], names[body], []byte(body), deps.Deps.logger)
+				assert.Equal(t, nil, err)
+				assert.Equal(t, expected, event.ToString())
+			}
+		})
+
+		t.Run("Loosened", func(t *testing.T) {
+			e := &webhookmodels.BranchProtectionRuleEventPayload{
+				Action: "edited",
+				Rule:   map[string]interface{}{"required_approving_review_count": float64(3), "allow_deletions_enforcement_level": "off"},
+			}
+			e.Changes = webhookmodels.SettingChanges{}
+			assert.Equal(t, false, e.Loosened())
+
+			raw := `{"required_approving_review_count":{"from":2},"allow_deletions_enforcement_level":{"from":"everyone"}}`
+			assert.Equal(t, nil, json.Unmarshal([]byte(raw), &e.Changes))
+			assert.Equal(t, false, e.Loosened())
+		})
+
+		t.Run("RepositoryEdited", func(t *testing.T) {
+			b := `{"action":"edited","changes":{"description":{"from":"*old* snake_case"},"homepage":{"from":""}},` +
+				`"repository":{"name":"test","full_name":"mwebster/test","html_url":"https://ghe.example.com/mwebster/test",` +
+				`"description":"new_value **bold**","homepage":"https://example.com/test_page"},"sender":{"login":"mwebster"}}`
+			event, err := parsePayload(providers[providerGitHub], "repository", []byte(b), deps.Deps.logger)
+			assert.Equal(t, nil, err)
+			expected := "*changed a repository's settings*\n<https://ghe.example.com/mwebster/test|Repository: mwebster/test>\n" +
+				"Description: *old* snake_case → new_value **bold**\nHomepage: none → https://example.com/test_page"
+			assert.Equal(t, expected, event.ToString())
+		})
+
+		t.Run("RepositoryRenamed", func(t *testing.T) {
+			b := []byte(`{"action":"renamed","changes":{"repository":{"name":{"from":"test"}}},` +
+				`"repository":{"name":"test-renamed","full_name":"mwebster/test-renamed","html_url":"https://ghe.example.com/mwebster/test-renamed"},` +
+				`"sender":{"login":"mwebster"}}`)
+			headers := map[string]string{"X-GitHub-Event": "repository", "X-Hub-Signature": "push"}
+			resp := performRequest(deps.Router, "POST", "/v1/github", signedHeaders(headers, b), b)
+			assert.Equal(t, CodeAccepted, resp.Code, resp.Body.String())
+
+			event, err := parsePayload(providers[providerGitHub], "repository", b, deps.Deps.logger)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, "test", event.Repository())
+			assert.T(t, strings.HasSuffix(event.ToString(), "\nName: test → test-renamed"), event.ToString())
+		})
+
+		t.Run("Destination", func(t *testing.T) {
+			event, err := parsePayload(providers[providerGitHub], "team_add", []byte(teamAdd), deps.Deps.logger)
+			assert.Equal(t, nil, err)
+			repo, channel := eventDestination(event)
+			assert.Equal(t, auditRepo, repo)
+			assert.Equal(t, "", channel)
+
+			var audit *dispatchers.TestDispatcher
+			for _, d := range deps.Deps.dispatchers {
+				if td, ok := d.(*dispatchers.TestDispatcher); ok && td.RepoName == auditRepo {
+					audit = td
+				}
+			}
+			deps.Deps.queue.announce(job{ctx: context.Background(), eventName: "team_add", event: event})
+			assert.T(t, strings.Contains(audit.MessageSent, "added a team to a repository"), audit.MessageSent)
+		})
+	})
+}
+
+func testProjects(t *testing.T, deps *testDeps) {
+	columnCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/graphql" && r.Header.Get("Accept") != inertiaPreview {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		switch r.URL.Path {
+		case "/projects/columns/11":
+			columnCalls++
+			fmt.Fprint(w, `{"id":11,"name":"In Review"}`)
+		case "/projects/columns/12":
+			fmt.Fprint(w, `{"id":12,"name":"Done"}`)
+		case "/repos/mwebster/test/issues/8":
+			fmt.Fprint(w, `{"number":8,"title":"Ship it","html_url":"https://ghe.example.com/mwebster/test/pull/8","pull_request":{}}`)
+		case "/api/graphql":
+			fmt.Fprint(w, `{"data":{"node":{"number":9,"title":"Fix login","url":"https://ghe.example.com/mwebster/test/issues/9"}}}`)
+		default:
+			w.WriteHeader(CodeNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("TestProjects", func(t *testing.T) {
+		t.Run("Card", func(t *testing.T) {
+			body := `{"action":"moved","project_card":{"id":1,"column_id":12,"content_url":"` + server.URL + `/repos/mwebster/test/issues/8"},` +
+				`"changes":{"column_id":{"from":11}},"repository":{"name":"test"},"sender":{"login":"mwebster"}}`
+			event, err := parsePayload(providers[providerGitHub], "project_card", []byte(body), deps.Deps.logger)
+			assert.Equal(t, nil, err)
+			card := event.(*webhookmodels.ProjectCardEventPayload)
+
+			enrichProjectCard(server.URL, card, deps.Deps.logger)
+			enrichProjectCard(server.URL, card, deps.Deps.logger)
+			assert.Equal(t, 1, columnCalls)
+			expected := "*moved a card*\n<https://ghe.example.com/mwebster/test/pull/8|Pull request #8: Ship it>\nColumn: In Review → Done"
+			assert.Equal(t, expected, card.ToString())
+
+			_, err = cardContent(server.URL, "https://attacker.example.com/repos/mwebster/test/issues/8", "token")
+			assert.NotEqual(t, nil, err)
+
+			note := &webhookmodels.ProjectCardEventPayload{Action: "created", Card: webhookmodels.Card{Note: "remember **this**"}}
+			assert.Equal(t, "*created a card*\n> remember *this*", note.ToString())
+		})
+
+		t.Run("ItemV2", func(t *testing.T) {
+			body := `{"action":"edited","projects_v2_item":{"node_id":"PVTI_1","content_node_id":"I_9","content_type":"Issue"},` +
+				`"changes":{"field_value":{"field_name":"Status","field_type":"single_select","project_number":4,` +
+				`"from":{"id":"a","name":"Todo"},"to":{"id":"b","name":"In Progress"}}},` +
+				`"organization":{"login":"mwebster"},"sender":{"login":"mwebster"}}`
+			event, err := parsePayload(providers[providerGitHub], "projects_v2_item", []byte(body), deps.Deps.logger)
+			assert.Equal(t, nil, err)
+			item := event.(*webhookmodels.ProjectsV2ItemEventPayload)
+			assert.Equal(t, "mwebster", item.Repository())
+
+			item.Content, err = itemContent(server.URL+"/api/v3", "token", &item.Item)
+			assert.Equal(t, nil, err)
+			expected := "*updated a project item*\n<https://ghe.example.com/mwebster/test/issues/9|Issue #9: Fix login>\n" +
+				"Project: #4\nStatus: Todo → In Progress"
+			assert.Equal(t, expected, item.ToString())
+
+			item.Changes.FieldValue.From = nil
+			item.Changes.FieldValue.To = "2026-10-18T00:00:00+00:00"
+			item.Changes.FieldValue.FieldName = "Due"
+			assert.T(t, strings.HasSuffix(item.ToString(), "\nDue: none → 2026-10-18"), item.ToString())
+
+			item.Action = "reordered"
+			assert.Equal(t, "", item.ToString())
+		})
+
+		t.Run("ItemV2Webhook", func(t *testing.T) {
+			b := []byte(`{"action":"created","projects_v2_item":{"node_id":"PVTI_2","content_type":"DraftIssue"},` +
+				`"organization":{"login":"test"},"sender":{"login":"mwebster"}}`)
+			headers := map[string]string{"X-GitHub-Event": "projects_v2_item", "X-Hub-Signature": "push"}
+			resp := performRequest(deps.Router, "POST", "/v1/github", signedHeaders(headers, b), b)
+			assert.Equal(t, CodeAccepted, resp.Code, resp.Body.String())
+		})
+
+		t.Run("GraphQLURL", func(t *testing.T) {
+			assert.Equal(t, "https://ghe.example.com/api/graphql", graphQLURL("https://ghe.example.com/api/v3"))
+			assert.Equal(t, "https://api.github.com/graphql", graphQLURL("https://api.github.com"))
+		})
+	})
+}
+
+func testRepoHousekeeping(t *testing.T, deps *testDeps) {
+	repo := `"repository":{"name":"test"},"sender":{"login":"mwebster"}`
+	commitComment := `{"action":"created","comment":{"html_url":"https://ghe.example.com/mwebster/test/commit/abc#r1",` +
+		`"commit_id":"0123456789abcdef","path":"main.go","line":12,"body":"why **this**?"},` + repo + `}`
+	milestone := `{"action":"edited","milestone":{"html_url":"https://ghe.example.com/mwebster/test/milestone/2","title":"v2",` +
+		`"open_issues":3,"closed_issues":5,"due_on":"2026-10-31T07:00:00Z"},"changes":{"due_on":{"from":"2026-10-24T07:00:00Z"}},` + repo + `}`
+	label := `{"action":"edited","label":{"name":"bug","color":"d73a4a","description":"Something isn't working"},` +
+		`"changes":{"name":{"from":"defect"},"color":{"from":"ee0701"}},` + repo + `}`
+
+	t.Run("TestRepoHousekeeping", func(t *testing.T) {
+		cases := []struct {
+			event    string
+			body     string
+			expected string
+		}{
+			{"commit_comment", commitComment, "*commented on a commit*\n<https://ghe.example.com/mwebster/test/commit/abc#r1|Commit: `0123456`>\n" +
+				"`main.go:12`\n> why *this*?"},
+			{"milestone", milestone, "*edited a milestone*\n<https://ghe.example.com/mwebster/test/milestone/2|Milestone: v2>\n" +
+				"Due: Oct 24, 2026 → Oct 31, 2026\nIssues: 3 open, 5 closed (62% complete)"},
+			{"label", label, "*edited a label*\nLabel: `bug` — Something isn't working\nName: ~defect~ → bug\nColor: #ee0701 → #d73a4a"},
+		}
+		for _, c := range cases {
+			t.Run(c.event, func(t *testing.T) {
+				headers := map[string]string{"X-GitHub-Event": c.event, "X-Hub-Signature": "push"}
+				resp := performRequest(deps.Router, "POST", "/v1/github", signedHeaders(headers, []byte(c.body)), []byte(c.body))
+				assert.Equal(t, CodeAccepted, resp.Code, resp.Body.String())
+
+				event, err := parsePayload(providers[providerGitHub], c.event, []byte(c.body), deps.Deps.logger)
+				assert.Equal(t, nil, err)
+				assert.Equal(t, c.expected, event.ToString())
+			})
+		}
+
+		t.Run("MilestoneDescriptionOnly", func(t *testing.T) {
+			e := &webhookmodels.MilestoneEventPayload{Action: "edited", Milestone: webhookmodels.Milestone{Title: "v2"}}
+			assert.Equal(t, "", e.ToString())
+		})
+	})
+}
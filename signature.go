@@ -0,0 +1,88 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"strings"
+
+	env "github.com/mike-webster/repo-watcher/env"
+)
+
+var (
+	errNoWatcher        = errors.New("no watcher configured for repo")
+	errNoSecrets        = errors.New("no secrets configured for watcher")
+	errMissingSignature = errors.New("no usable signature header")
+	errBadSignature     = errors.New("signature doesn't match any configured secret")
+	errBadToken         = errors.New("token doesn't match any configured secret")
+)
+
+// verifySignature checks the raw request body against the signatures sent
+// by GitHub using each of the watcher's secrets.  The sha256 signature is
+// always preferred; the sha1 signature is only checked when the watcher
+// allows it and no sha256 signature was sent.
+func verifySignature(w *env.Watcher, body []byte, sig256 string, sig1 string) error {
+	if w == nil {
+		return errNoWatcher
+	}
+
+	if len(w.Secrets) < 1 {
+		return errNoSecrets
+	}
+
+	var (
+		newHash func() hash.Hash
+		sig     string
+	)
+	switch {
+	case len(sig256) > 0:
+		newHash, sig = sha256.New, strings.TrimPrefix(sig256, "sha256=")
+	case len(sig1) > 0 && w.AllowSHA1:
+		newHash, sig = sha1.New, strings.TrimPrefix(sig1, "sha1=")
+	default:
+		return errMissingSignature
+	}
+
+	expected, err := hex.DecodeString(sig)
+	if err != nil {
+		return errBadSignature
+	}
+
+	for _, secret := range w.Secrets {
+		if len(secret) < 1 {
+			continue
+		}
+
+		mac := hmac.New(newHash, []byte(secret))
+		mac.Write(body)
+		if hmac.Equal(mac.Sum(nil), expected) {
+			return nil
+		}
+	}
+
+	return errBadSignature
+}
+
+// verifyToken checks a shared secret token, like gitlab's X-Gitlab-Token,
+// against each of the watcher's secrets.
+func verifyToken(w *env.Watcher, token string) error {
+	if w == nil {
+		return errNoWatcher
+	}
+
+	if len(w.Secrets) < 1 {
+		return errNoSecrets
+	}
+
+	for _, secret := range w.Secrets {
+		if len(secret) > 0 && subtle.ConstantTimeCompare([]byte(secret), []byte(token)) == 1 {
+			return nil
+		}
+	}
+
+	return errBadToken
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// DB is an embedded on-disk store.  Records are JSON encoded and grouped
+// into buckets; keys within a bucket are kept in byte order.
+type DB struct {
+	bolt *bolt.DB
+}
+
+// Open opens the store at the given path, creating it if needed.
+func Open(path string) (*DB, error) {
+	b, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DB{bolt: b}, nil
+}
+
+// Close releases the underlying file.
+func (db *DB) Close() error {
+	return db.bolt.Close()
+}
+
+// Put stores the value under the key in the bucket, replacing anything
+// that was already there.
+func (db *DB) Put(bucket string, key string, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return db.bolt.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+
+		return b.Put([]byte(key), raw)
+	})
+}
+
+// Get decodes the value stored under the key into value.  It returns false
+// if nothing is stored under the key.
+func (db *DB) Get(bucket string, key string, value interface{}) (bool, error) {
+	var raw []byte
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+
+		if v := b.Get([]byte(key)); v != nil {
+			// the slice is only valid for the life of the transaction
+			raw = append([]byte{}, v...)
+		}
+		return nil
+	})
+	if err != nil || raw == nil {
+		return false, err
+	}
+
+	return true, json.Unmarshal(raw, value)
+}
+
+// NextSequence returns the next number in the bucket's sequence.  Numbers
+// are never handed out twice, even to callers racing each other.
+func (db *DB) NextSequence(bucket string) (uint64, error) {
+	var seq uint64
+	err := db.bolt.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+
+		seq, err = b.NextSequence()
+		return err
+	})
+
+	return seq, err
+}
+
+// Delete removes the key from the bucket.
+func (db *DB) Delete(bucket string, key string) error {
+	return db.bolt.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+
+		return b.Delete([]byte(key))
+	})
+}
+
+// ForEach calls fn with every key and raw JSON value in the bucket in key
+// order.  Returning an error from fn stops the iteration.  raw is only
+// valid until fn returns.
+func (db *DB) ForEach(bucket string, fn func(key string, raw []byte) error) error {
+	return db.bolt.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+
+		return b.ForEach(func(k, v []byte) error {
+			return fn(string(k), v)
+		})
+	})
+}
+
+// Purge removes every record in the bucket.
+func (db *DB) Purge(bucket string) error {
+	return db.bolt.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte(bucket)) == nil {
+			return nil
+		}
+
+		return tx.DeleteBucket([]byte(bucket))
+	})
+}
@@ -26,11 +26,17 @@ func MakeRequest(url string, id string, token string) (*[]byte, error) {
 		return nil, err
 	}
 
+	return doRequest(req)
+}
+
+// doRequest sends the request and returns the body of a 200 response.
+func doRequest(req *http.Request) (*[]byte, error) {
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
 		return nil, errors.New(fmt.Sprint("non-200: ", resp.StatusCode))
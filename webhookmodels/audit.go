@@ -0,0 +1,105 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AuditEvent is implemented by repository administration payloads, which
+// are announced in the audit channel instead of the repo's.
+type AuditEvent interface {
+	Event
+	Audited()
+}
+
+// enforcementLevels ranks branch protection enforcement levels, least
+// enforced first.
+var enforcementLevels = map[string]int{
+	"off":        0,
+	"non_admins": 1,
+	"everyone":   2,
+}
+
+// SettingChanges holds the previous values of the settings an edit changed,
+// keyed by setting name.
+type SettingChanges map[string]struct {
+	From interface{} `json:"from"`
+}
+
+// settingLines renders each changed setting as "setting: old → new", in
+// name order.  current holds the new values.
+func settingLines(changes SettingChanges, current map[string]interface{}) []string {
+	keys := []string{}
+	for k := range changes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	lines := []string{}
+	for _, k := range keys {
+		lines = append(lines, fmt.Sprintf("%s: %s → %s", settingName(k), settingValue(changes[k].From), settingValue(current[k])))
+	}
+	return lines
+}
+
+// settingName turns a setting key into words, e.g. allow_force_pushes
+func settingName(key string) string {
+	return strings.Replace(strings.TrimSuffix(key, "_enforcement_level"), "_", " ", -1)
+}
+
+func settingValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "none"
+	case bool:
+		if v {
+			return "on"
+		}
+		return "off"
+	case []interface{}:
+		if len(v) < 1 {
+			return "none"
+		}
+		values := []string{}
+		for _, i := range v {
+			values = append(values, fmt.Sprint(i))
+		}
+		return strings.Join(values, ", ")
+	case string:
+		if len(v) < 1 {
+			return "none"
+		}
+		return strings.Replace(v, "_", " ", -1)
+	}
+	return fmt.Sprint(value)
+}
+
+// loosens returns true if changing the setting from one value to the other
+// makes the protection weaker: a check turned off, fewer required reviews or
+// status checks, or a lower enforcement level.  Settings named allow_* work
+// the other way around.
+func loosens(key string, from interface{}, to interface{}) bool {
+	weaker := false
+	switch f := from.(type) {
+	case bool:
+		t, _ := to.(bool)
+		weaker = f && !t
+	case float64:
+		t, _ := to.(float64)
+		weaker = t < f
+	case []interface{}:
+		t, _ := to.([]interface{})
+		weaker = len(t) < len(f)
+	case string:
+		t, _ := to.(string)
+		fl, fok := enforcementLevels[f]
+		tl, tok := enforcementLevels[t]
+		weaker = fok && tok && tl < fl
+	}
+
+	if strings.HasPrefix(key, "allow_") {
+		return !weaker && fmt.Sprint(from) != fmt.Sprint(to)
+	}
+	return weaker
+}
@@ -0,0 +1,53 @@
+package webhookmodels
+
+import "fmt"
+
+// BitbucketUser represents a bitbucket server user
+type BitbucketUser struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+	Slug         string `json:"slug"`
+}
+
+// BitbucketLinks are the links bitbucket server includes on its objects
+type BitbucketLinks struct {
+	Self []struct {
+		Href string `json:"href"`
+	} `json:"self"`
+}
+
+// URL returns the first self link, if there is one
+func (bl *BitbucketLinks) URL() string {
+	if len(bl.Self) < 1 {
+		return ""
+	}
+	return bl.Self[0].Href
+}
+
+// BitbucketRepository represents a bitbucket server repository
+type BitbucketRepository struct {
+	ID      int64  `json:"id"`
+	Slug    string `json:"slug"`
+	Name    string `json:"name"`
+	Project struct {
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	} `json:"project"`
+	Links BitbucketLinks `json:"links"`
+}
+
+// FullName returns the project key and repo slug used to match watchers,
+// e.g. "PROJ/repo"
+func (br *BitbucketRepository) FullName() string {
+	return fmt.Sprintf("%s/%s", br.Project.Key, br.Slug)
+}
+
+// BitbucketRef represents a branch or tag on one side of a pull request
+type BitbucketRef struct {
+	ID           string              `json:"id"`
+	DisplayID    string              `json:"displayId"`
+	LatestCommit string              `json:"latestCommit"`
+	Repository   BitbucketRepository `json:"repository"`
+}
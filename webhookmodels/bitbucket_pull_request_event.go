@@ -0,0 +1,83 @@
+package webhookmodels
+
+import (
+	"fmt"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// BitbucketPullRequestEventPayload is the request received for every pr:*
+// event from bitbucket server: opened, modified, merged, declined, deleted,
+// reviewer changes and comments.
+//
+// https://confluence.atlassian.com/bitbucketserver/event-payload-938025882.html#Eventpayload-Pullrequest
+type BitbucketPullRequestEventPayload struct {
+	EventKey    string        `json:"eventKey" binding:"required"`
+	Actor       BitbucketUser `json:"actor"`
+	PullRequest struct {
+		ID          int64          `json:"id"`
+		Title       string         `json:"title"`
+		Description string         `json:"description"`
+		State       string         `json:"state"`
+		FromRef     BitbucketRef   `json:"fromRef"`
+		ToRef       BitbucketRef   `json:"toRef"`
+		Links       BitbucketLinks `json:"links"`
+	} `json:"pullRequest"`
+	Comment *struct {
+		ID   int64  `json:"id"`
+		Text string `json:"text"`
+	} `json:"comment"`
+}
+
+// ToString outputs a summary message of the event
+func (bprep *BitbucketPullRequestEventPayload) ToString() string {
+	pr := bprep.PullRequest
+	title := markdown.MarkdownLink(pr.Links.URL(), fmt.Sprintf("Title: %s", pr.Title))
+	switch bprep.EventKey {
+	case "pr:opened":
+		header := markdown.MarkdownBold("opened a pull request")
+		body := markdown.MarkdownMultilineCode(pr.Description)
+		return fmt.Sprintf("%s\n%s\n%s", header, title, body)
+	case "pr:merged":
+		header := markdown.MarkdownBold(fmt.Sprintf("merged a pull request into %s", pr.ToRef.DisplayID))
+		return fmt.Sprintf("%s\n%s", header, title)
+	case "pr:declined":
+		return fmt.Sprintf("%s\n%s", markdown.MarkdownBold("declined a pull request"), title)
+	case "pr:deleted":
+		return fmt.Sprintf("%s\n%s", markdown.MarkdownBold("deleted a pull request"), title)
+	case "pr:reviewer:approved":
+		return fmt.Sprintf("%s\n%s", markdown.MarkdownBold("approved a pull request"), title)
+	case "pr:reviewer:unapproved":
+		return fmt.Sprintf("%s\n%s", markdown.MarkdownBold("removed their approval from a pull request"), title)
+	case "pr:reviewer:needs_work":
+		return fmt.Sprintf("%s\n%s", markdown.MarkdownBold("marked a pull request as needs work"), title)
+	case "pr:comment:added", "pr:comment:edited":
+		action := "commented on"
+		if bprep.EventKey == "pr:comment:edited" {
+			action = "edited a comment on"
+		}
+		header := markdown.MarkdownBold(fmt.Sprintf("%s a pull request", action))
+		comment := ""
+		if bprep.Comment != nil {
+			comment = bprep.Comment.Text
+		}
+		return fmt.Sprintf("%s\n%s\n%s", header, title, markdown.MarkdownMultilineCode(comment))
+	}
+
+	// modified, from_ref_updated and the rest are too noisy to announce
+	return ""
+}
+
+// Username returns the username of the user who triggered the event
+func (bprep *BitbucketPullRequestEventPayload) Username() string {
+	return bprep.Actor.Name
+}
+
+// DisplayName returns the name of the user who triggered the event
+func (bprep *BitbucketPullRequestEventPayload) DisplayName() string {
+	return bprep.Actor.DisplayName
+}
+
+func (bprep *BitbucketPullRequestEventPayload) Repository() string {
+	return bprep.PullRequest.ToRef.Repository.FullName()
+}
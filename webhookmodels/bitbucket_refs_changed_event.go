@@ -0,0 +1,67 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// BitbucketRefsChangedEventPayload is the request received when branches or
+// tags are pushed, created or deleted in a bitbucket server repository.
+//
+// https://confluence.atlassian.com/bitbucketserver/event-payload-938025882.html#Eventpayload-Push
+type BitbucketRefsChangedEventPayload struct {
+	EventKey   string              `json:"eventKey" binding:"required"`
+	Actor      BitbucketUser       `json:"actor"`
+	Repo       BitbucketRepository `json:"repository"`
+	RefChanges []struct {
+		Ref struct {
+			ID        string `json:"id"`
+			DisplayID string `json:"displayId"`
+			Type      string `json:"type"`
+		} `json:"ref"`
+		FromHash string `json:"fromHash"`
+		ToHash   string `json:"toHash"`
+		Type     string `json:"type"`
+	} `json:"changes"`
+}
+
+// ToString outputs a summary message of the event
+func (brcep *BitbucketRefsChangedEventPayload) ToString() string {
+	lines := []string{}
+	for _, c := range brcep.RefChanges {
+		kind := strings.ToLower(c.Ref.Type)
+		switch c.Type {
+		case "ADD":
+			lines = append(lines, fmt.Sprintf("created a %s: %s", kind, c.Ref.DisplayID))
+		case "DELETE":
+			lines = append(lines, fmt.Sprintf("deleted a %s: %s", kind, c.Ref.DisplayID))
+		default:
+			lines = append(lines, fmt.Sprintf("pushed some changes to %s (%s..%s)", c.Ref.DisplayID, shortSHA(c.FromHash), shortSHA(c.ToHash)))
+		}
+	}
+	if len(lines) < 1 {
+		return ""
+	}
+
+	header := markdown.MarkdownLink(brcep.Repo.Links.URL(), lines[0])
+	if len(lines) == 1 {
+		return header
+	}
+	return fmt.Sprintf("%s\n%s", header, markdown.MarkdownList(lines[1:]))
+}
+
+// Username returns the username of the user who triggered the event
+func (brcep *BitbucketRefsChangedEventPayload) Username() string {
+	return brcep.Actor.Name
+}
+
+// DisplayName returns the name of the user who triggered the event
+func (brcep *BitbucketRefsChangedEventPayload) DisplayName() string {
+	return brcep.Actor.DisplayName
+}
+
+func (brcep *BitbucketRefsChangedEventPayload) Repository() string {
+	return brcep.Repo.FullName()
+}
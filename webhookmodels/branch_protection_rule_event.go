@@ -0,0 +1,73 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// BranchProtectionRuleEventPayload is the request received when a branch
+// protection rule is created, edited, or deleted.
+//
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#branch_protection_rule
+type BranchProtectionRuleEventPayload struct {
+	Action string `json:"action" binding:"required"`
+	// Rule is kept as its raw settings so any changed setting can be shown
+	// next to its new value.
+	Rule    map[string]interface{} `json:"rule"`
+	Changes SettingChanges         `json:"changes"`
+	Repo    Repository             `json:"repository"`
+	Sender  User                   `json:"sender"`
+}
+
+// ToString outputs a summary message of the event
+func (bprep *BranchProtectionRuleEventPayload) ToString() string {
+	var header string
+	switch bprep.Action {
+	case "created":
+		header = "added a branch protection rule"
+	case "deleted":
+		header = ":warning: removed a branch protection rule"
+	case "edited":
+		header = "changed a branch protection rule"
+		if bprep.Loosened() {
+			header = ":warning: loosened a branch protection rule"
+		}
+	default:
+		header = fmt.Sprintf("%s a branch protection rule", bprep.Action)
+	}
+
+	lines := []string{
+		markdown.MarkdownBold(header),
+		fmt.Sprintf("Repository: %s", bprep.Repo.Slug()),
+		fmt.Sprintf("Branches: `%v`", bprep.Rule["name"]),
+	}
+	if bprep.Action == "edited" {
+		lines = append(lines, settingLines(bprep.Changes, bprep.Rule)...)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Loosened returns true if any of the edit's changes weakens the rule
+func (bprep *BranchProtectionRuleEventPayload) Loosened() bool {
+	for key, change := range bprep.Changes {
+		if loosens(key, change.From, bprep.Rule[key]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Audited marks the event for the audit channel
+func (bprep *BranchProtectionRuleEventPayload) Audited() {}
+
+// Username returns the username of the user who triggered the event
+func (bprep *BranchProtectionRuleEventPayload) Username() string {
+	return bprep.Sender.Login
+}
+
+func (bprep *BranchProtectionRuleEventPayload) Repository() string {
+	return bprep.Repo.Name
+}
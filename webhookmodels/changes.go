@@ -0,0 +1,124 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// maxDiffLines caps how much of a body diff is rendered.
+const maxDiffLines = 20
+
+// maxDiffInput caps the lines compared, so a huge body can't stall a worker.
+const maxDiffInput = 500
+
+var reCheckbox = regexp.MustCompile(`^([*+-]\s+)\[[ xX]\]`)
+
+// Changes holds the previous values of whatever an edit changed.  Fields that
+// weren't changed are nil.
+type Changes struct {
+	Title *ChangedValue `json:"title"`
+	Body  *ChangedValue `json:"body"`
+}
+
+// ChangedValue is the value a field had before an edit
+type ChangedValue struct {
+	From string `json:"from"`
+}
+
+// editMessage renders what an edit changed: the old and new title and a line
+// diff of the body.  Edits that only touch whitespace or tick checkboxes
+// return an empty string, so they aren't announced.
+func editMessage(header string, link string, changes *Changes, title string, body string) string {
+	lines := []string{header, link}
+	edited := false
+
+	if changes.Title != nil && strings.TrimSpace(changes.Title.From) != strings.TrimSpace(title) {
+		edited = true
+		lines = append(lines, fmt.Sprintf("Title: ~%s~ → %s", changes.Title.From, title))
+	}
+	if changes.Body != nil {
+		if diff := lineDiff(changes.Body.From, body); len(diff) > 0 {
+			edited = true
+			lines = append(lines, markdown.MarkdownMultilineCode(diff))
+		}
+	}
+
+	if !edited {
+		return ""
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lineDiff returns the removed and added lines between the two bodies,
+// ignoring whitespace and checkbox state, or an empty string if there's no
+// difference.
+func lineDiff(from string, to string) string {
+	a, b := diffLines(from), diffLines(to)
+	na, nb := len(a), len(b)
+
+	// lcs[i][j] is the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, na+1)
+	for i := range lcs {
+		lcs[i] = make([]int, nb+1)
+	}
+	for i := na - 1; i >= 0; i-- {
+		for j := nb - 1; j >= 0; j-- {
+			if a[i].key == b[j].key {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	out := []string{}
+	i, j := 0, 0
+	for i < na || j < nb {
+		switch {
+		case i < na && j < nb && a[i].key == b[j].key:
+			i, j = i+1, j+1
+			continue
+		case i < na && (j == nb || lcs[i+1][j] >= lcs[i][j+1]):
+			out = append(out, "- "+a[i].text)
+			i++
+		default:
+			out = append(out, "+ "+b[j].text)
+			j++
+		}
+	}
+
+	if len(out) > maxDiffLines {
+		out = append(out[:maxDiffLines], fmt.Sprintf("… %d more lines", len(out)-maxDiffLines))
+	}
+	return strings.Join(out, "\n")
+}
+
+type diffLine struct {
+	key  string
+	text string
+}
+
+// diffLines splits a body into the lines to compare.  Blank lines are
+// dropped, and each line is keyed without its surrounding whitespace or
+// checkbox state.
+func diffLines(body string) []diffLine {
+	ret := []diffLine{}
+	for _, l := range strings.Split(strings.Replace(body, "\r\n", "\n", -1), "\n") {
+		text := strings.TrimSpace(l)
+		if len(text) < 1 {
+			continue
+		}
+
+		key := strings.Join(strings.Fields(reCheckbox.ReplaceAllString(text, "$1[ ]")), " ")
+		ret = append(ret, diffLine{key: key, text: text})
+		if len(ret) == maxDiffInput {
+			break
+		}
+	}
+	return ret
+}
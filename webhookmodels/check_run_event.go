@@ -0,0 +1,75 @@
+package webhookmodels
+
+// CheckRunEventPayload is the request received when a check run is created,
+// completed, rerequested or has a requested action.
+//
+// https://developer.github.com/v3/activity/events/types/#checkrunevent
+type CheckRunEventPayload struct {
+	Action   string `json:"action" binding:"required"`
+	CheckRun struct {
+		Name       string `json:"name"`
+		HeadSHA    string `json:"head_sha"`
+		Status     string `json:"status"`
+		Conclusion string `json:"conclusion"`
+		URL        string `json:"html_url"`
+		DetailsURL string `json:"details_url"`
+		Output     struct {
+			Title string `json:"title"`
+		} `json:"output"`
+		CheckSuite struct {
+			HeadBranch string `json:"head_branch"`
+		} `json:"check_suite"`
+		PullRequests []checkPullRequest `json:"pull_requests"`
+	} `json:"check_run"`
+	Repo     Repository  `json:"repository"`
+	Sender   User        `json:"sender"`
+	Recovery *CIRecovery `json:"-"`
+}
+
+// CIResult returns the check run as a CI result.
+func (crep *CheckRunEventPayload) CIResult() CIResult {
+	cr := crep.CheckRun
+	url := cr.DetailsURL
+	if len(url) < 1 {
+		url = cr.URL
+	}
+
+	branches := []string{}
+	if len(cr.CheckSuite.HeadBranch) > 0 {
+		branches = append(branches, cr.CheckSuite.HeadBranch)
+	}
+
+	return CIResult{
+		Repo:          crep.Repo.Slug(),
+		DefaultBranch: crep.Repo.DefaultBranch,
+		Context:       cr.Name,
+		State:         checkState(cr.Status, cr.Conclusion),
+		SHA:           cr.HeadSHA,
+		Branches:      branches,
+		URL:           url,
+		Description:   cr.Output.Title,
+		PullRequests:  checkPullRequestNumbers(cr.PullRequests),
+	}
+}
+
+func (crep *CheckRunEventPayload) SetRecovery(r *CIRecovery) {
+	crep.Recovery = r
+}
+
+// ToString outputs a summary message of the event.  Only completed runs are
+// announced.
+func (crep *CheckRunEventPayload) ToString() string {
+	if crep.Action != "completed" {
+		return ""
+	}
+	return ciMessage(crep.CIResult(), crep.Recovery)
+}
+
+// Username returns the username of the user who triggered the event
+func (crep *CheckRunEventPayload) Username() string {
+	return crep.Sender.Login
+}
+
+func (crep *CheckRunEventPayload) Repository() string {
+	return crep.Repo.Name
+}
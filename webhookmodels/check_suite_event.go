@@ -0,0 +1,70 @@
+package webhookmodels
+
+// CheckSuiteEventPayload is the request received when a check suite is
+// completed, requested or rerequested.
+//
+// https://developer.github.com/v3/activity/events/types/#checksuiteevent
+type CheckSuiteEventPayload struct {
+	Action     string `json:"action" binding:"required"`
+	CheckSuite struct {
+		HeadBranch string `json:"head_branch"`
+		HeadSHA    string `json:"head_sha"`
+		Status     string `json:"status"`
+		Conclusion string `json:"conclusion"`
+		App        struct {
+			Name string `json:"name"`
+		} `json:"app"`
+		PullRequests []checkPullRequest `json:"pull_requests"`
+	} `json:"check_suite"`
+	Repo     Repository  `json:"repository"`
+	Sender   User        `json:"sender"`
+	Recovery *CIRecovery `json:"-"`
+}
+
+// CIResult returns the check suite as a CI result.  Suites don't have a page
+// of their own, so the commit's checks tab is linked.
+func (csep *CheckSuiteEventPayload) CIResult() CIResult {
+	cs := csep.CheckSuite
+	branches := []string{}
+	if len(cs.HeadBranch) > 0 {
+		branches = append(branches, cs.HeadBranch)
+	}
+
+	url := ""
+	if len(csep.Repo.URL) > 0 {
+		url = csep.Repo.URL + "/commit/" + cs.HeadSHA + "/checks"
+	}
+
+	return CIResult{
+		Repo:          csep.Repo.Slug(),
+		DefaultBranch: csep.Repo.DefaultBranch,
+		Context:       cs.App.Name,
+		State:         checkState(cs.Status, cs.Conclusion),
+		SHA:           cs.HeadSHA,
+		Branches:      branches,
+		URL:           url,
+		PullRequests:  checkPullRequestNumbers(cs.PullRequests),
+	}
+}
+
+func (csep *CheckSuiteEventPayload) SetRecovery(r *CIRecovery) {
+	csep.Recovery = r
+}
+
+// ToString outputs a summary message of the event.  Only completed suites
+// are announced.
+func (csep *CheckSuiteEventPayload) ToString() string {
+	if csep.Action != "completed" {
+		return ""
+	}
+	return ciMessage(csep.CIResult(), csep.Recovery)
+}
+
+// Username returns the username of the user who triggered the event
+func (csep *CheckSuiteEventPayload) Username() string {
+	return csep.Sender.Login
+}
+
+func (csep *CheckSuiteEventPayload) Repository() string {
+	return csep.Repo.Name
+}
@@ -0,0 +1,115 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// CI states, normalized across status and check events
+const (
+	CIPending = "pending"
+	CISuccess = "success"
+	CIFailure = "failure"
+)
+
+// CIResult is a CI outcome for a commit, whichever event reported it
+type CIResult struct {
+	// Repo is the owner/name slug of the repo
+	Repo          string
+	DefaultBranch string
+	Context       string
+	State         string
+	SHA           string
+	Branches      []string
+	URL           string
+	Description   string
+	// PullRequests are the numbers of the open pull requests for the commit,
+	// when the event knows them.
+	PullRequests []int
+}
+
+// OnBranch returns true if the commit is on the branch.
+func (cr *CIResult) OnBranch(branch string) bool {
+	for _, b := range cr.Branches {
+		if b == branch {
+			return true
+		}
+	}
+	return false
+}
+
+// CIRecovery marks a green result for something that was red before.
+type CIRecovery struct {
+	// Author is who to tell about it, usually the pull request author
+	Author string
+}
+
+// CIEvent is implemented by the payloads that report CI results.
+type CIEvent interface {
+	Event
+	CIResult() CIResult
+	// SetRecovery is called before rendering when the result is a recovery
+	SetRecovery(r *CIRecovery)
+}
+
+// ciMessage renders a CI result the same way for every CI event.
+func ciMessage(result CIResult, recovery *CIRecovery) string {
+	var header string
+	switch {
+	case recovery != nil:
+		header = markdown.MarkdownBold(fmt.Sprintf("%s is green again", result.Context))
+	case result.State == CIFailure:
+		header = markdown.MarkdownBold(fmt.Sprintf("%s failed", result.Context))
+	case result.State == CISuccess:
+		header = markdown.MarkdownBold(fmt.Sprintf("%s passed", result.Context))
+	default:
+		return ""
+	}
+
+	commit := markdown.MarkdownCode(shortSHA(result.SHA))
+	if len(result.Branches) > 0 {
+		commit = fmt.Sprintf("%s on %s", commit, strings.Join(result.Branches, ", "))
+	}
+
+	lines := []string{header, fmt.Sprint("Commit: ", commit)}
+	if len(result.URL) > 0 {
+		lines = append(lines, markdown.MarkdownLink(result.URL, "Details"))
+	}
+	if len(result.Description) > 0 {
+		lines = append(lines, markdown.MarkdownItalic(result.Description))
+	}
+	if recovery != nil && len(recovery.Author) > 0 {
+		lines = append(lines, fmt.Sprint("cc ", recovery.Author))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// checkState normalizes a check run or suite's status and conclusion.
+func checkState(status string, conclusion string) string {
+	if status != "completed" {
+		return CIPending
+	}
+
+	switch conclusion {
+	case "success", "neutral", "skipped":
+		return CISuccess
+	case "stale":
+		return CIPending
+	}
+	return CIFailure
+}
+
+type checkPullRequest struct {
+	Number int `json:"number"`
+}
+
+func checkPullRequestNumbers(prs []checkPullRequest) []int {
+	ret := []int{}
+	for _, pr := range prs {
+		ret = append(ret, pr.Number)
+	}
+	return ret
+}
@@ -0,0 +1,102 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// CodeScanningAlertEventPayload is the request received when a code scanning
+// alert is created, fixed, reopened, closed, or appears in another branch.
+//
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#code_scanning_alert
+type CodeScanningAlertEventPayload struct {
+	Action string            `json:"action" binding:"required"`
+	Alert  CodeScanningAlert `json:"alert"`
+	Ref    string            `json:"ref"`
+	Repo   Repository        `json:"repository"`
+	Sender User              `json:"sender"`
+}
+
+// CodeScanningAlert is a problem a code scanning tool found in the repo
+type CodeScanningAlert struct {
+	Number int    `json:"number"`
+	State  string `json:"state"`
+	URL    string `json:"html_url"`
+	Rule   struct {
+		ID                    string `json:"id"`
+		Name                  string `json:"name"`
+		Description           string `json:"description"`
+		Severity              string `json:"severity"`
+		SecuritySeverityLevel string `json:"security_severity_level"`
+	} `json:"rule"`
+	Tool struct {
+		Name string `json:"name"`
+	} `json:"tool"`
+	MostRecentInstance struct {
+		Ref      string `json:"ref"`
+		Location struct {
+			Path      string `json:"path"`
+			StartLine int    `json:"start_line"`
+		} `json:"location"`
+	} `json:"most_recent_instance"`
+	DismissedReason  string `json:"dismissed_reason"`
+	DismissedComment string `json:"dismissed_comment"`
+}
+
+// ToString outputs a summary message of the event
+func (csaep *CodeScanningAlertEventPayload) ToString() string {
+	a := &csaep.Alert
+	title := a.Rule.Description
+	if len(title) < 1 {
+		title = a.Rule.Name
+	}
+
+	lines := []string{
+		markdown.MarkdownBold(alertHeader(csaep.Action, "code scanning")),
+		markdown.MarkdownLink(a.URL, fmt.Sprintf("#%d %s", a.Number, title)),
+		severityLine(csaep.Severity()),
+	}
+
+	rule := fmt.Sprintf("Rule: `%s`", a.Rule.ID)
+	if len(a.Tool.Name) > 0 {
+		rule = fmt.Sprintf("%s (%s)", rule, a.Tool.Name)
+	}
+	lines = append(lines, rule)
+
+	if loc := a.MostRecentInstance.Location; len(loc.Path) > 0 {
+		file := fmt.Sprintf("File: `%s:%d`", loc.Path, loc.StartLine)
+		ref := a.MostRecentInstance.Ref
+		if len(csaep.Ref) > 0 {
+			ref = csaep.Ref
+		}
+		if len(ref) > 0 {
+			file = fmt.Sprintf("%s on `%s`", file, ShortRef(ref))
+		}
+		lines = append(lines, file)
+	}
+	if reason := dismissalLine(a.DismissedReason, a.DismissedComment); len(reason) > 0 && csaep.Action == "closed_by_user" {
+		lines = append(lines, reason)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Severity returns the rule's security severity, or its plain severity for
+// rules that aren't security related.
+func (csaep *CodeScanningAlertEventPayload) Severity() string {
+	if level := csaep.Alert.Rule.SecuritySeverityLevel; len(level) > 0 {
+		return normalizeSeverity(level)
+	}
+	return normalizeSeverity(csaep.Alert.Rule.Severity)
+}
+
+// Username returns the username of the user who triggered the event
+func (csaep *CodeScanningAlertEventPayload) Username() string {
+	return csaep.Sender.Login
+}
+
+func (csaep *CodeScanningAlertEventPayload) Repository() string {
+	return csaep.Repo.Name
+}
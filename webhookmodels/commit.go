@@ -0,0 +1,32 @@
+package webhookmodels
+
+import (
+	"strings"
+	"time"
+)
+
+// Commit represents a commit in a push
+type Commit struct {
+	ID        string       `json:"id"`
+	Message   string       `json:"message"`
+	Timestamp time.Time    `json:"timestamp"`
+	URL       string       `json:"url"`
+	Distinct  bool         `json:"distinct"`
+	Author    CommitAuthor `json:"author"`
+	Committer CommitAuthor `json:"committer"`
+	Added     []string     `json:"added"`
+	Removed   []string     `json:"removed"`
+	Modified  []string     `json:"modified"`
+}
+
+// CommitAuthor is the git identity on a commit
+type CommitAuthor struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+}
+
+// Title returns the first line of the commit message
+func (c *Commit) Title() string {
+	return strings.SplitN(strings.TrimSpace(c.Message), "\n", 2)[0]
+}
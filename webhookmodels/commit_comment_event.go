@@ -0,0 +1,59 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// CommitCommentEventPayload is the request received when a commit comment is
+// created.
+//
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#commit_comment
+type CommitCommentEventPayload struct {
+	Action  string        `json:"action" binding:"required"`
+	Comment CommitComment `json:"comment"`
+	Repo    Repository    `json:"repository"`
+	Sender  User          `json:"sender"`
+}
+
+// CommitComment is a comment on a commit, or on one of its lines
+type CommitComment struct {
+	ID       int64  `json:"id"`
+	URL      string `json:"html_url"`
+	CommitID string `json:"commit_id"`
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Body     string `json:"body"`
+	User     User   `json:"user"`
+}
+
+// ToString outputs a summary message of the event
+func (ccep *CommitCommentEventPayload) ToString() string {
+	c := &ccep.Comment
+	lines := []string{
+		markdown.MarkdownBold("commented on a commit"),
+		markdown.MarkdownLink(c.URL, fmt.Sprintf("Commit: `%s`", shortSHA(c.CommitID))),
+	}
+	switch {
+	case len(c.Path) > 0 && c.Line > 0:
+		lines = append(lines, fmt.Sprintf("`%s:%d`", c.Path, c.Line))
+	case len(c.Path) > 0:
+		lines = append(lines, fmt.Sprintf("`%s`", c.Path))
+	}
+	if body := strings.TrimSpace(c.Body); len(body) > 0 {
+		lines = append(lines, quote(markdown.ToSlack(body)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Username returns the username of the user who triggered the event
+func (ccep *CommitCommentEventPayload) Username() string {
+	return ccep.Sender.Login
+}
+
+func (ccep *CommitCommentEventPayload) Repository() string {
+	return ccep.Repo.Name
+}
@@ -0,0 +1,38 @@
+package webhookmodels
+
+import (
+	"fmt"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// DeleteEventPayload is the request received when a branch or tag is deleted
+// from a repository.
+//
+// https://developer.github.com/v3/activity/events/types/#deleteevent
+type DeleteEventPayload struct {
+	Type   string     `json:"ref_type"`
+	Ref    string     `json:"ref" binding:"required"`
+	Repo   Repository `json:"repository"`
+	Sender User       `json:"sender"`
+	// Protected is set when the watcher protects the ref.  Those deletions
+	// are announced by the push event instead, which has the SHAs.
+	Protected bool `json:"-"`
+}
+
+// ToString outputs a summary message of the event
+func (dlep *DeleteEventPayload) ToString() string {
+	if dlep.Protected {
+		return ""
+	}
+	return fmt.Sprintf("deleted a %v: %v", dlep.Type, markdown.MarkdownCode(dlep.Ref))
+}
+
+// Username returns the username of the user who triggered the event
+func (dlep *DeleteEventPayload) Username() string {
+	return dlep.Sender.Login
+}
+
+func (dlep *DeleteEventPayload) Repository() string {
+	return dlep.Repo.Name
+}
@@ -0,0 +1,95 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// DependabotAlertEventPayload is the request received when a dependabot alert
+// is created, dismissed, fixed, reintroduced, or reopened.
+//
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#dependabot_alert
+type DependabotAlertEventPayload struct {
+	Action string          `json:"action" binding:"required"`
+	Alert  DependabotAlert `json:"alert"`
+	Repo   Repository      `json:"repository"`
+	Sender User            `json:"sender"`
+}
+
+// DependabotAlert is a vulnerable dependency found in one of the repo's
+// manifests
+type DependabotAlert struct {
+	Number     int    `json:"number"`
+	State      string `json:"state"`
+	URL        string `json:"html_url"`
+	Dependency struct {
+		Package      SecurityPackage `json:"package"`
+		ManifestPath string          `json:"manifest_path"`
+		Scope        string          `json:"scope"`
+	} `json:"dependency"`
+	SecurityAdvisory struct {
+		GHSAID   string `json:"ghsa_id"`
+		CVEID    string `json:"cve_id"`
+		Summary  string `json:"summary"`
+		Severity string `json:"severity"`
+	} `json:"security_advisory"`
+	SecurityVulnerability struct {
+		VulnerableVersionRange string `json:"vulnerable_version_range"`
+		FirstPatchedVersion    *struct {
+			Identifier string `json:"identifier"`
+		} `json:"first_patched_version"`
+	} `json:"security_vulnerability"`
+	DismissedReason  string `json:"dismissed_reason"`
+	DismissedComment string `json:"dismissed_comment"`
+}
+
+// SecurityPackage is the package an advisory applies to
+type SecurityPackage struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+}
+
+// ToString outputs a summary message of the event
+func (daep *DependabotAlertEventPayload) ToString() string {
+	a := &daep.Alert
+	title := a.SecurityAdvisory.Summary
+	if len(a.SecurityAdvisory.CVEID) > 0 {
+		title = fmt.Sprintf("%s (%s)", title, a.SecurityAdvisory.CVEID)
+	}
+
+	lines := []string{
+		markdown.MarkdownBold(alertHeader(daep.Action, "dependabot")),
+		markdown.MarkdownLink(a.URL, fmt.Sprintf("#%d %s", a.Number, title)),
+		severityLine(daep.Severity()),
+		fmt.Sprintf("Package: `%s` (%s) in `%s`", a.Dependency.Package.Name, a.Dependency.Package.Ecosystem, a.Dependency.ManifestPath),
+	}
+
+	if vulnerable := a.SecurityVulnerability.VulnerableVersionRange; len(vulnerable) > 0 {
+		line := fmt.Sprintf("Vulnerable: `%s`", vulnerable)
+		if patched := a.SecurityVulnerability.FirstPatchedVersion; patched != nil && len(patched.Identifier) > 0 {
+			line = fmt.Sprintf("%s, patched in `%s`", line, patched.Identifier)
+		}
+		lines = append(lines, line)
+	}
+	if reason := dismissalLine(a.DismissedReason, a.DismissedComment); len(reason) > 0 && strings.HasSuffix(daep.Action, "dismissed") {
+		lines = append(lines, reason)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Severity returns the severity of the advisory
+func (daep *DependabotAlertEventPayload) Severity() string {
+	return normalizeSeverity(daep.Alert.SecurityAdvisory.Severity)
+}
+
+// Username returns the username of the user who triggered the event
+func (daep *DependabotAlertEventPayload) Username() string {
+	return daep.Sender.Login
+}
+
+func (daep *DependabotAlertEventPayload) Repository() string {
+	return daep.Repo.Name
+}
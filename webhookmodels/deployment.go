@@ -0,0 +1,126 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// Deployment represents a github deployment
+type Deployment struct {
+	ID          int64     `json:"id"`
+	SHA         string    `json:"sha"`
+	Ref         string    `json:"ref"`
+	Task        string    `json:"task"`
+	Environment string    `json:"environment"`
+	Description string    `json:"description"`
+	Creator     User      `json:"creator"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// summary lists what's being deployed, shared by both deployment events
+func (d *Deployment) summary() []string {
+	lines := []string{fmt.Sprintf("Ref: %s (%s)", markdown.MarkdownCode(d.Ref), markdown.MarkdownCode(shortSHA(d.SHA)))}
+	if len(d.Creator.Login) > 0 {
+		lines = append(lines, fmt.Sprint("Created by: ", d.Creator.Login))
+	}
+	if len(d.Description) > 0 {
+		lines = append(lines, markdown.MarkdownItalic(d.Description))
+	}
+	return lines
+}
+
+// DeploymentEventPayload is the request received when a deployment is
+// created.
+//
+// https://developer.github.com/v3/activity/events/types/#deploymentevent
+type DeploymentEventPayload struct {
+	Action     string     `json:"action"`
+	Deployment Deployment `json:"deployment"`
+	Repo       Repository `json:"repository"`
+	Sender     User       `json:"sender"`
+}
+
+// ToString outputs a summary message of the event
+func (dep *DeploymentEventPayload) ToString() string {
+	header := markdown.MarkdownBold(fmt.Sprintf("started deployment #%d to %s", dep.Deployment.ID, dep.Deployment.Environment))
+	return strings.Join(append([]string{header}, dep.Deployment.summary()...), "\n")
+}
+
+// Username returns the username of the user who triggered the event
+func (dep *DeploymentEventPayload) Username() string {
+	return dep.Sender.Login
+}
+
+func (dep *DeploymentEventPayload) Repository() string {
+	return dep.Repo.Name
+}
+
+// DeploymentStatusEventPayload is the request received when a deployment's
+// status changes.
+//
+// https://developer.github.com/v3/activity/events/types/#deploymentstatusevent
+type DeploymentStatusEventPayload struct {
+	Action           string `json:"action"`
+	DeploymentStatus struct {
+		ID             int64  `json:"id"`
+		State          string `json:"state" binding:"required"`
+		Description    string `json:"description"`
+		Environment    string `json:"environment"`
+		TargetURL      string `json:"target_url"`
+		LogURL         string `json:"log_url"`
+		EnvironmentURL string `json:"environment_url"`
+	} `json:"deployment_status"`
+	Deployment Deployment `json:"deployment"`
+	Repo       Repository `json:"repository"`
+	Sender     User       `json:"sender"`
+	// States are the deployment's earlier states, oldest first, so the
+	// message can show how it got here.
+	States []string `json:"-"`
+}
+
+// ToString outputs a summary message of the event
+func (dsep *DeploymentStatusEventPayload) ToString() string {
+	ds := dsep.DeploymentStatus
+	environment := ds.Environment
+	if len(environment) < 1 {
+		environment = dsep.Deployment.Environment
+	}
+
+	header := markdown.MarkdownBold(fmt.Sprintf("deployment #%d to %s: %s", dsep.Deployment.ID, environment, ds.State))
+	lines := append([]string{header}, dsep.Deployment.summary()...)
+	if len(dsep.States) > 0 {
+		lines = append(lines, fmt.Sprint("History: ", strings.Join(append(dsep.States, ds.State), " → ")))
+	}
+	if len(ds.Description) > 0 && ds.Description != dsep.Deployment.Description {
+		lines = append(lines, markdown.MarkdownItalic(ds.Description))
+	}
+
+	links := []string{}
+	logURL := ds.LogURL
+	if len(logURL) < 1 {
+		logURL = ds.TargetURL
+	}
+	if len(logURL) > 0 {
+		links = append(links, markdown.MarkdownLink(logURL, "Logs"))
+	}
+	if len(ds.EnvironmentURL) > 0 {
+		links = append(links, markdown.MarkdownLink(ds.EnvironmentURL, "Environment"))
+	}
+	if len(links) > 0 {
+		lines = append(lines, strings.Join(links, " | "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Username returns the username of the user who triggered the event
+func (dsep *DeploymentStatusEventPayload) Username() string {
+	return dsep.Sender.Login
+}
+
+func (dsep *DeploymentStatusEventPayload) Repository() string {
+	return dsep.Repo.Name
+}
@@ -0,0 +1,83 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// DiscussionEvent is implemented by discussion payloads so they can be
+// routed by category.
+type DiscussionEvent interface {
+	Event
+	Category() string
+}
+
+// Discussion represents a github discussion
+type Discussion struct {
+	ID             int64              `json:"id"`
+	Number         int                `json:"number"`
+	URL            string             `json:"html_url"`
+	Title          string             `json:"title"`
+	Body           string             `json:"body"`
+	User           User               `json:"user"`
+	State          string             `json:"state"`
+	Category       DiscussionCategory `json:"category"`
+	AnswerURL      string             `json:"answer_html_url"`
+	AnswerChosenBy *User              `json:"answer_chosen_by"`
+}
+
+// DiscussionCategory is the category a discussion was posted in
+type DiscussionCategory struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	Emoji        string `json:"emoji"`
+	IsAnswerable bool   `json:"is_answerable"`
+}
+
+// DiscussionComment is a comment or reply on a discussion
+type DiscussionComment struct {
+	ID       int64  `json:"id"`
+	URL      string `json:"html_url"`
+	Body     string `json:"body"`
+	User     User   `json:"user"`
+	ParentID int64  `json:"parent_id"`
+}
+
+// Answered returns true if one of the discussion's comments is marked as
+// the answer.
+func (d *Discussion) Answered() bool {
+	return len(d.AnswerURL) > 0
+}
+
+// lines returns the category, linked title and answer state shared by
+// discussion messages.
+func (d *Discussion) lines(url string) []string {
+	category := d.Category.Name
+	if len(d.Category.Emoji) > 0 {
+		category = fmt.Sprintf("%s %s", d.Category.Emoji, category)
+	}
+
+	lines := []string{
+		fmt.Sprintf("Category: %s", category),
+		markdown.MarkdownLink(url, fmt.Sprintf("Title: %s", d.Title)),
+	}
+	if d.Category.IsAnswerable {
+		if d.Answered() {
+			lines = append(lines, ":white_check_mark: Answered")
+		} else {
+			lines = append(lines, "Unanswered")
+		}
+	}
+	return lines
+}
+
+// discussionBody quotes the body, or returns an empty string when there's
+// nothing to quote.
+func discussionBody(body string) string {
+	if len(strings.TrimSpace(body)) < 1 {
+		return ""
+	}
+	return quote(markdown.ToSlack(body))
+}
@@ -0,0 +1,60 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// DiscussionCommentEventPayload is the request received when a comment on a
+// discussion is created, edited, or deleted.
+//
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#discussion_comment
+type DiscussionCommentEventPayload struct {
+	Action     string            `json:"action" binding:"required"`
+	Comment    DiscussionComment `json:"comment"`
+	Discussion Discussion        `json:"discussion"`
+	Changes    Changes           `json:"changes"`
+	Repo       Repository        `json:"repository"`
+	Sender     User              `json:"sender"`
+}
+
+// ToString outputs a summary message of the event
+func (dcep *DiscussionCommentEventPayload) ToString() string {
+	header := fmt.Sprintf("%s a comment on a discussion", dcep.Action)
+	if dcep.Action == "created" && dcep.Comment.ParentID > 0 {
+		header = "replied to a comment on a discussion"
+	}
+	header = markdown.MarkdownBold(header)
+
+	url := dcep.Comment.URL
+	if len(url) < 1 {
+		url = dcep.Discussion.URL
+	}
+	lines := dcep.Discussion.lines(url)
+	if dcep.Action == "edited" {
+		return editMessage(header, strings.Join(lines, "\n"), &dcep.Changes, "", dcep.Comment.Body)
+	}
+
+	lines = append([]string{header}, lines...)
+	if body := discussionBody(dcep.Comment.Body); len(body) > 0 && dcep.Action != "deleted" {
+		lines = append(lines, body)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Category returns the name of the discussion's category
+func (dcep *DiscussionCommentEventPayload) Category() string {
+	return dcep.Discussion.Category.Name
+}
+
+// Username returns the username of the user who triggered the event
+func (dcep *DiscussionCommentEventPayload) Username() string {
+	return dcep.Sender.Login
+}
+
+func (dcep *DiscussionCommentEventPayload) Repository() string {
+	return dcep.Repo.Name
+}
@@ -0,0 +1,75 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// DiscussionEventPayload is the request received when a discussion is
+// created, edited, deleted, pinned, unpinned, locked, unlocked, transferred,
+// answered, unanswered, labeled, unlabeled, or its category changes.
+//
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#discussion
+type DiscussionEventPayload struct {
+	Action     string             `json:"action" binding:"required"`
+	Discussion Discussion         `json:"discussion"`
+	Answer     *DiscussionComment `json:"answer"`
+	Changes    Changes            `json:"changes"`
+	Repo       Repository         `json:"repository"`
+	Sender     User               `json:"sender"`
+}
+
+// ToString outputs a summary message of the event
+func (dep *DiscussionEventPayload) ToString() string {
+	var header string
+	switch dep.Action {
+	case "created":
+		header = "started a discussion"
+	case "answered":
+		header = "marked an answer to a discussion"
+	case "unanswered":
+		header = "unmarked the answer to a discussion"
+	case "category_changed":
+		header = "moved a discussion"
+	default:
+		header = fmt.Sprintf("%s a discussion", dep.Action)
+	}
+	header = markdown.MarkdownBold(header)
+
+	d := &dep.Discussion
+	lines := d.lines(d.URL)
+	if dep.Action == "edited" {
+		return editMessage(header, strings.Join(lines, "\n"), &dep.Changes, d.Title, d.Body)
+	}
+
+	lines = append([]string{header}, lines...)
+	var body string
+	switch {
+	case dep.Action == "created":
+		body = discussionBody(d.Body)
+	case dep.Action == "answered" && dep.Answer != nil:
+		lines = append(lines, markdown.MarkdownLink(dep.Answer.URL, fmt.Sprintf("Answer by %s", dep.Answer.User.Login)))
+		body = discussionBody(dep.Answer.Body)
+	}
+	if len(body) > 0 {
+		lines = append(lines, body)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Category returns the name of the discussion's category
+func (dep *DiscussionEventPayload) Category() string {
+	return dep.Discussion.Category.Name
+}
+
+// Username returns the username of the user who triggered the event
+func (dep *DiscussionEventPayload) Username() string {
+	return dep.Sender.Login
+}
+
+func (dep *DiscussionEventPayload) Repository() string {
+	return dep.Repo.Name
+}
@@ -6,3 +6,9 @@ type Event interface {
 	Username() string
 	Repository() string
 }
+
+// NamedEvent is implemented by payloads that already carry the display name
+// of the user who triggered them, so it doesn't need to be looked up on GHE.
+type NamedEvent interface {
+	DisplayName() string
+}
@@ -0,0 +1,116 @@
+package webhookmodels
+
+// Gitea sends payloads shaped like GitHub's, so the gitea payloads reuse the
+// github ones and only swap out what differs: the sender, who has a full
+// name and isn't a GHE user, and the push compare link.
+
+// GiteaUser represents the user who triggered a gitea event
+type GiteaUser struct {
+	ID       int64  `json:"id"`
+	Login    string `json:"login"`
+	Username string `json:"username"`
+	FullName string `json:"full_name"`
+}
+
+// Name returns the full name of the user, falling back to their login.
+func (gu *GiteaUser) Name() string {
+	if len(gu.FullName) > 0 {
+		return gu.FullName
+	}
+	return gu.Login
+}
+
+// GiteaPushEventPayload is the request received when commits are pushed to
+// a gitea branch.
+//
+// https://docs.gitea.com/usage/webhooks
+type GiteaPushEventPayload struct {
+	PushEventPayload
+	CompareURL string    `json:"compare_url"`
+	Sender     GiteaUser `json:"sender"`
+}
+
+// ToString outputs a summary message of the event
+func (gpep *GiteaPushEventPayload) ToString() string {
+	pep := gpep.PushEventPayload
+	pep.URL = gpep.CompareURL
+	return pep.ToString()
+}
+
+// Username returns the username of the user who triggered the event
+func (gpep *GiteaPushEventPayload) Username() string {
+	return gpep.Sender.Login
+}
+
+// DisplayName returns the name of the user who triggered the event
+func (gpep *GiteaPushEventPayload) DisplayName() string {
+	return gpep.Sender.Name()
+}
+
+// GiteaPullRequestEventPayload is the request received when a gitea pull
+// request changes.
+type GiteaPullRequestEventPayload struct {
+	PullRequestEventPayload
+	Sender GiteaUser `json:"sender"`
+}
+
+// Username returns the username of the user who triggered the event
+func (gprep *GiteaPullRequestEventPayload) Username() string {
+	return gprep.Sender.Login
+}
+
+// DisplayName returns the name of the user who triggered the event
+func (gprep *GiteaPullRequestEventPayload) DisplayName() string {
+	return gprep.Sender.Name()
+}
+
+// GiteaIssuesEventPayload is the request received when a gitea issue
+// changes.
+type GiteaIssuesEventPayload struct {
+	IssuesEventPayload
+	Sender GiteaUser `json:"sender"`
+}
+
+// Username returns the username of the user who triggered the event
+func (giep *GiteaIssuesEventPayload) Username() string {
+	return giep.Sender.Login
+}
+
+// DisplayName returns the name of the user who triggered the event
+func (giep *GiteaIssuesEventPayload) DisplayName() string {
+	return giep.Sender.Name()
+}
+
+// GiteaIssueCommentEventPayload is the request received when someone
+// comments on a gitea issue or pull request.
+type GiteaIssueCommentEventPayload struct {
+	IssueCommentEventPayload
+	Sender GiteaUser `json:"sender"`
+}
+
+// Username returns the username of the user who triggered the event
+func (gicep *GiteaIssueCommentEventPayload) Username() string {
+	return gicep.Sender.Login
+}
+
+// DisplayName returns the name of the user who triggered the event
+func (gicep *GiteaIssueCommentEventPayload) DisplayName() string {
+	return gicep.Sender.Name()
+}
+
+// GiteaReleaseEventPayload is the request received when a gitea release is
+// published, updated or deleted.
+type GiteaReleaseEventPayload struct {
+	ReleaseEventPayload
+	Sender GiteaUser `json:"sender"`
+}
+
+// Username returns the username of the user who triggered the event
+func (grep *GiteaReleaseEventPayload) Username() string {
+	return grep.Sender.Login
+}
+
+// DisplayName returns the name of the user who triggered the event
+func (grep *GiteaReleaseEventPayload) DisplayName() string {
+	return grep.Sender.Name()
+}
@@ -0,0 +1,33 @@
+package webhookmodels
+
+import "time"
+
+// GitLabUser represents the user who triggered a gitlab event
+type GitLabUser struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+}
+
+// GitLabProject represents a gitlab project
+type GitLabProject struct {
+	ID                int64  `json:"id"`
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	URL               string `json:"web_url"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	DefaultBranch     string `json:"default_branch"`
+}
+
+// GitLabCommit represents a commit in a gitlab push
+type GitLabCommit struct {
+	ID        string    `json:"id"`
+	Message   string    `json:"message"`
+	Title     string    `json:"title"`
+	Timestamp time.Time `json:"timestamp"`
+	URL       string    `json:"url"`
+	Author    struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"author"`
+}
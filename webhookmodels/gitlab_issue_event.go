@@ -0,0 +1,63 @@
+package webhookmodels
+
+import (
+	"fmt"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// GitLabIssueEventPayload is the request received when an issue is opened,
+// updated, closed or reopened.
+//
+// https://docs.gitlab.com/ee/user/project/integrations/webhooks.html#issue-events
+type GitLabIssueEventPayload struct {
+	Kind             string        `json:"object_kind"`
+	User             GitLabUser    `json:"user"`
+	Project          GitLabProject `json:"project"`
+	ObjectAttributes struct {
+		IID         int    `json:"iid"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		URL         string `json:"url"`
+		Action      string `json:"action" binding:"required"`
+		State       string `json:"state"`
+	} `json:"object_attributes"`
+}
+
+// ToString outputs a summary message of the event
+func (glie *GitLabIssueEventPayload) ToString() string {
+	attrs := glie.ObjectAttributes
+	actions := map[string]string{
+		"open":   "opened",
+		"update": "updated",
+		"close":  "closed",
+		"reopen": "reopened",
+	}
+	action, ok := actions[attrs.Action]
+	if !ok {
+		action = attrs.Action
+	}
+
+	header := markdown.MarkdownBold(fmt.Sprintf("%s an issue", action))
+	title := markdown.MarkdownLink(attrs.URL, fmt.Sprintf("Title: %s", attrs.Title))
+	if attrs.Action == "open" {
+		body := markdown.MarkdownMultilineCode(attrs.Description)
+		return fmt.Sprintf("%s\n%s\n%s", header, title, body)
+	}
+
+	return fmt.Sprintf("%s\n%s", header, title)
+}
+
+// Username returns the username of the user who triggered the event
+func (glie *GitLabIssueEventPayload) Username() string {
+	return glie.User.Username
+}
+
+// DisplayName returns the name of the user who triggered the event
+func (glie *GitLabIssueEventPayload) DisplayName() string {
+	return glie.User.Name
+}
+
+func (glie *GitLabIssueEventPayload) Repository() string {
+	return glie.Project.Name
+}
@@ -0,0 +1,65 @@
+package webhookmodels
+
+import (
+	"fmt"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// GitLabMergeRequestEventPayload is the request received when a merge request
+// is opened, updated, approved, merged, closed or reopened.
+//
+// https://docs.gitlab.com/ee/user/project/integrations/webhooks.html#merge-request-events
+type GitLabMergeRequestEventPayload struct {
+	Kind             string        `json:"object_kind"`
+	User             GitLabUser    `json:"user"`
+	Project          GitLabProject `json:"project"`
+	ObjectAttributes struct {
+		IID          int    `json:"iid"`
+		Title        string `json:"title"`
+		Description  string `json:"description"`
+		URL          string `json:"url"`
+		Action       string `json:"action" binding:"required"`
+		State        string `json:"state"`
+		SourceBranch string `json:"source_branch"`
+		TargetBranch string `json:"target_branch"`
+	} `json:"object_attributes"`
+}
+
+// ToString outputs a summary message of the event
+func (glmrep *GitLabMergeRequestEventPayload) ToString() string {
+	attrs := glmrep.ObjectAttributes
+	title := markdown.MarkdownLink(attrs.URL, fmt.Sprintf("Title: %s", attrs.Title))
+	switch attrs.Action {
+	case "open", "reopen":
+		header := markdown.MarkdownBold(fmt.Sprintf("%sed a merge request", attrs.Action))
+		body := markdown.MarkdownMultilineCode(attrs.Description)
+		return fmt.Sprintf("%s\n%s\n%s", header, title, body)
+	case "merge":
+		header := markdown.MarkdownBold(fmt.Sprintf("merged a merge request into %s", attrs.TargetBranch))
+		return fmt.Sprintf("%s\n%s", header, title)
+	case "close":
+		header := markdown.MarkdownBold("closed a merge request")
+		return fmt.Sprintf("%s\n%s", header, title)
+	case "approved", "unapproved":
+		header := markdown.MarkdownBold(fmt.Sprintf("%s a merge request", attrs.Action))
+		return fmt.Sprintf("%s\n%s", header, title)
+	}
+
+	// updates fire on every push to the source branch
+	return ""
+}
+
+// Username returns the username of the user who triggered the event
+func (glmrep *GitLabMergeRequestEventPayload) Username() string {
+	return glmrep.User.Username
+}
+
+// DisplayName returns the name of the user who triggered the event
+func (glmrep *GitLabMergeRequestEventPayload) DisplayName() string {
+	return glmrep.User.Name
+}
+
+func (glmrep *GitLabMergeRequestEventPayload) Repository() string {
+	return glmrep.Project.Name
+}
@@ -0,0 +1,71 @@
+package webhookmodels
+
+import (
+	"fmt"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// GitLabNoteEventPayload is the request received when a comment is made on a
+// commit, merge request, issue or snippet.
+//
+// https://docs.gitlab.com/ee/user/project/integrations/webhooks.html#comment-events
+type GitLabNoteEventPayload struct {
+	Kind             string        `json:"object_kind"`
+	User             GitLabUser    `json:"user"`
+	Project          GitLabProject `json:"project"`
+	ObjectAttributes struct {
+		Note         string `json:"note" binding:"required"`
+		NoteableType string `json:"noteable_type"`
+		URL          string `json:"url"`
+	} `json:"object_attributes"`
+	MergeRequest *struct {
+		IID   int    `json:"iid"`
+		Title string `json:"title"`
+	} `json:"merge_request"`
+	Issue *struct {
+		IID   int    `json:"iid"`
+		Title string `json:"title"`
+	} `json:"issue"`
+	Commit *struct {
+		ID      string `json:"id"`
+		Message string `json:"message"`
+	} `json:"commit"`
+}
+
+// ToString outputs a summary message of the event
+func (glnep *GitLabNoteEventPayload) ToString() string {
+	attrs := glnep.ObjectAttributes
+	var header, title string
+	switch {
+	case glnep.MergeRequest != nil:
+		header = "commented on a merge request"
+		title = fmt.Sprintf("Title: %s", glnep.MergeRequest.Title)
+	case glnep.Issue != nil:
+		header = "commented on an issue"
+		title = fmt.Sprintf("Title: %s", glnep.Issue.Title)
+	case glnep.Commit != nil:
+		header = "commented on a commit"
+		title = fmt.Sprintf("Commit: %s", shortSHA(glnep.Commit.ID))
+	default:
+		header = "commented on a snippet"
+		title = "View comment"
+	}
+
+	comment := markdown.MarkdownMultilineCode(attrs.Note)
+	return fmt.Sprintf("%s\n%s\n%s", markdown.MarkdownBold(header), markdown.MarkdownLink(attrs.URL, title), comment)
+}
+
+// Username returns the username of the user who triggered the event
+func (glnep *GitLabNoteEventPayload) Username() string {
+	return glnep.User.Username
+}
+
+// DisplayName returns the name of the user who triggered the event
+func (glnep *GitLabNoteEventPayload) DisplayName() string {
+	return glnep.User.Name
+}
+
+func (glnep *GitLabNoteEventPayload) Repository() string {
+	return glnep.Project.Name
+}
@@ -0,0 +1,66 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// GitLabPipelineEventPayload is the request received when a pipeline's
+// status changes.
+//
+// https://docs.gitlab.com/ee/user/project/integrations/webhooks.html#pipeline-events
+type GitLabPipelineEventPayload struct {
+	Kind             string        `json:"object_kind"`
+	User             GitLabUser    `json:"user"`
+	Project          GitLabProject `json:"project"`
+	ObjectAttributes struct {
+		ID       int64  `json:"id"`
+		Ref      string `json:"ref"`
+		SHA      string `json:"sha"`
+		Status   string `json:"status" binding:"required"`
+		Duration int64  `json:"duration"`
+	} `json:"object_attributes"`
+}
+
+// ToString outputs a summary message of the event
+func (glpep *GitLabPipelineEventPayload) ToString() string {
+	attrs := glpep.ObjectAttributes
+	switch attrs.Status {
+	case "success", "failed", "canceled":
+	default:
+		// only announce pipelines once they're finished
+		return ""
+	}
+
+	url := fmt.Sprintf("%s/-/pipelines/%d", glpep.Project.URL, attrs.ID)
+	header := markdown.MarkdownBold(fmt.Sprintf("ran a pipeline that %s", pipelineOutcome(attrs.Status)))
+	title := markdown.MarkdownLink(url, fmt.Sprintf("Pipeline #%d on %s", attrs.ID, attrs.Ref))
+	detail := fmt.Sprintf("Commit: %s -- Duration: %s", markdown.MarkdownCode(shortSHA(attrs.SHA)), time.Duration(attrs.Duration)*time.Second)
+	return fmt.Sprintf("%s\n%s\n%s", header, title, detail)
+}
+
+func pipelineOutcome(status string) string {
+	switch status {
+	case "success":
+		return "passed"
+	case "canceled":
+		return "was canceled"
+	}
+	return status
+}
+
+// Username returns the username of the user who triggered the event
+func (glpep *GitLabPipelineEventPayload) Username() string {
+	return glpep.User.Username
+}
+
+// DisplayName returns the name of the user who triggered the event
+func (glpep *GitLabPipelineEventPayload) DisplayName() string {
+	return glpep.User.Name
+}
+
+func (glpep *GitLabPipelineEventPayload) Repository() string {
+	return glpep.Project.Name
+}
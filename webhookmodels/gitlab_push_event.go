@@ -0,0 +1,60 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// GitLabPushEventPayload is the request received when commits are pushed to
+// a gitlab branch.  Tag pushes use the same payload.
+//
+// https://docs.gitlab.com/ee/user/project/integrations/webhooks.html#push-events
+type GitLabPushEventPayload struct {
+	Kind         string         `json:"object_kind"`
+	Ref          string         `json:"ref" binding:"required"`
+	Before       string         `json:"before"`
+	After        string         `json:"after"`
+	UserName     string         `json:"user_name"`
+	UserUsername string         `json:"user_username"`
+	Project      GitLabProject  `json:"project"`
+	Commits      []GitLabCommit `json:"commits"`
+	TotalCommits int            `json:"total_commits_count"`
+}
+
+// CompareURL returns a link to the changes in the push
+func (glpep *GitLabPushEventPayload) CompareURL() string {
+	return fmt.Sprintf("%s/-/compare/%s...%s", glpep.Project.URL, glpep.Before, glpep.After)
+}
+
+// ToString outputs a summary message of the event
+func (glpep *GitLabPushEventPayload) ToString() string {
+	if glpep.Kind == "tag_push" {
+		tag := strings.TrimPrefix(glpep.Ref, "refs/tags/")
+		return fmt.Sprintf("pushed a tag: %s", markdown.MarkdownLink(fmt.Sprintf("%s/-/tags/%s", glpep.Project.URL, tag), tag))
+	}
+
+	messages := []string{}
+	for _, c := range glpep.Commits {
+		messages = append(messages, c.Title)
+	}
+	header := markdown.MarkdownLink(glpep.CompareURL(), fmt.Sprintf("pushed some changes to %s", glpep.Ref))
+	title := markdown.MarkdownItalic("Commits:")
+	body := markdown.MarkdownMultilineCode(strings.Join(messages, "\n"))
+	return fmt.Sprintf("%s\n%s\n%s", header, title, body)
+}
+
+// Username returns the username of the user who triggered the event
+func (glpep *GitLabPushEventPayload) Username() string {
+	return glpep.UserUsername
+}
+
+// DisplayName returns the name of the user who triggered the event
+func (glpep *GitLabPushEventPayload) DisplayName() string {
+	return glpep.UserName
+}
+
+func (glpep *GitLabPushEventPayload) Repository() string {
+	return glpep.Project.Name
+}
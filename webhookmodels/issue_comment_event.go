@@ -12,6 +12,7 @@ type IssueCommentEventPayload struct {
 	Action  string       `json:"action"  binding:"required"`
 	Issue   Issue        `json:"issue"`
 	Comment IssueComment `json:"comment"`
+	Changes Changes      `json:"changes"`
 	Repo    Repository   `json:"repository"`
 	Sender  User         `json:"sender"`
 }
@@ -24,7 +25,10 @@ type IssueComment struct {
 func (icep *IssueCommentEventPayload) ToString() string {
 	header := markdown.MarkdownBold(fmt.Sprintf("%s a comment on an issue", icep.Action))
 	title := markdown.MarkdownLink(icep.Issue.URL, fmt.Sprintf("Title: %s", icep.Issue.Title))
-	comment := markdown.MarkdownMultilineCode(fmt.Sprintf(icep.Comment.Body))
+	if icep.Action == "edited" {
+		return editMessage(header, title, &icep.Changes, "", icep.Comment.Body)
+	}
+	comment := markdown.MarkdownMultilineCode(icep.Comment.Body)
 	return fmt.Sprintf("%s\n%s\n%s", header, title, comment)
 }
 
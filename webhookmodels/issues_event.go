@@ -10,17 +10,20 @@ import "github.com/mike-webster/repo-watcher/markdown"
 //
 // https://developer.github.com/v3/activity/events/types/#issuesevent
 type IssuesEventPayload struct {
-	Action  string      `json:"action"  binding:"required"`
-	Issue   Issue       `json:"issue"`
-	Changes interface{} `json:"changes"`
-	Repo    Repository  `json:"repository"`
-	Sender  User        `json:"sender"`
+	Action  string     `json:"action"  binding:"required"`
+	Issue   Issue      `json:"issue"`
+	Changes Changes    `json:"changes"`
+	Repo    Repository `json:"repository"`
+	Sender  User       `json:"sender"`
 }
 
 // ToString outputs a summary message of the event
 func (iep *IssuesEventPayload) ToString() string {
 	header := markdown.MarkdownBold(fmt.Sprintf("%v an issue", iep.Action))
 	title := markdown.MarkdownLink(iep.Issue.URL, fmt.Sprintf("Title: %v", iep.Issue.Title))
+	if iep.Action == "edited" {
+		return editMessage(header, title, &iep.Changes, iep.Issue.Title, iep.Issue.Body)
+	}
 	body := markdown.MarkdownMultilineCode(iep.Issue.Body)
 	return fmt.Sprintf("%s\n%s\n%s", header, title, body)
 }
@@ -2,10 +2,11 @@ package webhookmodels
 
 // Label represents a github label
 type Label struct {
-	ID     int64  `json:"id"`
-	NodeID string `json:"node_id"`
-	Name   string `json:"name"`
-	Color  string `json:"color"`
+	ID          int64  `json:"id"`
+	NodeID      string `json:"node_id"`
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
 }
 
 type Labels []Label
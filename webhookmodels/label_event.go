@@ -0,0 +1,63 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// LabelEventPayload is the request received when a label is created,
+// edited, or deleted.
+//
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#label
+type LabelEventPayload struct {
+	Action  string `json:"action" binding:"required"`
+	Label   Label  `json:"label"`
+	Changes struct {
+		Name        *ChangedValue `json:"name"`
+		Color       *ChangedValue `json:"color"`
+		Description *ChangedValue `json:"description"`
+	} `json:"changes"`
+	Repo   Repository `json:"repository"`
+	Sender User       `json:"sender"`
+}
+
+// ToString outputs a summary message of the event
+func (lep *LabelEventPayload) ToString() string {
+	l := &lep.Label
+	label := fmt.Sprintf("Label: `%s`", l.Name)
+	if len(l.Description) > 0 && lep.Changes.Description == nil {
+		label = fmt.Sprintf("%s — %s", label, l.Description)
+	}
+	lines := []string{
+		markdown.MarkdownBold(fmt.Sprintf("%s a label", lep.Action)),
+		label,
+	}
+
+	if lep.Action == "edited" {
+		c := &lep.Changes
+		if c.Name != nil {
+			lines = append(lines, fmt.Sprintf("Name: ~%s~ → %s", c.Name.From, l.Name))
+		}
+		if c.Color != nil {
+			lines = append(lines, fmt.Sprintf("Color: #%s → #%s", c.Color.From, l.Color))
+		}
+		if c.Description != nil {
+			lines = append(lines, fmt.Sprintf("Description: %s → %s", orNone(c.Description.From), orNone(l.Description)))
+		}
+	} else if len(l.Color) > 0 {
+		lines = append(lines, fmt.Sprintf("Color: #%s", l.Color))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Username returns the username of the user who triggered the event
+func (lep *LabelEventPayload) Username() string {
+	return lep.Sender.Login
+}
+
+func (lep *LabelEventPayload) Repository() string {
+	return lep.Repo.Name
+}
@@ -0,0 +1,90 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// MemberEventPayload is the request received when a collaborator is added to
+// or removed from a repository, or their permissions change.
+//
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#member
+type MemberEventPayload struct {
+	Action  string `json:"action" binding:"required"`
+	Member  User   `json:"member"`
+	Changes struct {
+		Permission    *ValueChange `json:"permission"`
+		OldPermission *ValueChange `json:"old_permission"`
+		RoleName      *ValueChange `json:"role_name"`
+	} `json:"changes"`
+	Repo   Repository `json:"repository"`
+	Sender User       `json:"sender"`
+}
+
+// ValueChange is a setting's value before and after a change.  Either side
+// may be missing.
+type ValueChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ToString outputs a summary message of the event
+func (mep *MemberEventPayload) ToString() string {
+	header := fmt.Sprintf("%s a collaborator", mep.Action)
+	if mep.Action == "edited" {
+		header = "changed a collaborator's permission"
+	}
+
+	from, to := mep.permission()
+	if to == "admin" && from != "admin" {
+		header = fmt.Sprintf(":warning: %s", header)
+	}
+
+	lines := []string{
+		markdown.MarkdownBold(header),
+		fmt.Sprintf("Repository: %s", mep.Repo.Slug()),
+		markdown.MarkdownLink(mep.Member.URL, fmt.Sprintf("Collaborator: %s", mep.Member.Login)),
+	}
+	switch {
+	case len(from) > 0 && len(to) > 0:
+		lines = append(lines, fmt.Sprintf("Permission: %s → %s", from, to))
+	case len(to) > 0:
+		lines = append(lines, fmt.Sprintf("Permission: %s", to))
+	case len(from) > 0:
+		lines = append(lines, fmt.Sprintf("Permission: ~%s~", from))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// permission returns the collaborator's permission before and after the
+// change, whichever are known.  Older payloads only send the old value.
+func (mep *MemberEventPayload) permission() (string, string) {
+	var from, to string
+	for _, c := range []*ValueChange{mep.Changes.OldPermission, mep.Changes.Permission, mep.Changes.RoleName} {
+		if c == nil {
+			continue
+		}
+		if len(c.From) > 0 && len(from) < 1 {
+			from = c.From
+		}
+		if len(c.To) > 0 && len(to) < 1 {
+			to = c.To
+		}
+	}
+	return from, to
+}
+
+// Audited marks the event for the audit channel
+func (mep *MemberEventPayload) Audited() {}
+
+// Username returns the username of the user who triggered the event
+func (mep *MemberEventPayload) Username() string {
+	return mep.Sender.Login
+}
+
+func (mep *MemberEventPayload) Repository() string {
+	return mep.Repo.Name
+}
@@ -0,0 +1,91 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// MilestoneEventPayload is the request received when a milestone is created,
+// closed, opened, edited, or deleted.
+//
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#milestone
+type MilestoneEventPayload struct {
+	Action    string    `json:"action" binding:"required"`
+	Milestone Milestone `json:"milestone"`
+	Changes   struct {
+		Title *ChangedValue `json:"title"`
+		DueOn *struct {
+			From *time.Time `json:"from"`
+		} `json:"due_on"`
+	} `json:"changes"`
+	Repo   Repository `json:"repository"`
+	Sender User       `json:"sender"`
+}
+
+// Milestone represents a github milestone
+type Milestone struct {
+	ID           int64      `json:"id"`
+	Number       int        `json:"number"`
+	URL          string     `json:"html_url"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	State        string     `json:"state"`
+	OpenIssues   int        `json:"open_issues"`
+	ClosedIssues int        `json:"closed_issues"`
+	DueOn        *time.Time `json:"due_on"`
+}
+
+// ToString outputs a summary message of the event.  Edits are only announced
+// when they rename the milestone or move its due date.
+func (mep *MilestoneEventPayload) ToString() string {
+	m := &mep.Milestone
+	c := &mep.Changes
+	if mep.Action == "edited" && c.Title == nil && c.DueOn == nil {
+		return ""
+	}
+
+	header := fmt.Sprintf("%s a milestone", mep.Action)
+	if mep.Action == "opened" {
+		header = "reopened a milestone"
+	}
+	lines := []string{
+		markdown.MarkdownBold(header),
+		markdown.MarkdownLink(m.URL, fmt.Sprintf("Milestone: %s", m.Title)),
+	}
+
+	if c.Title != nil && mep.Action == "edited" {
+		lines = append(lines, fmt.Sprintf("Title: ~%s~ → %s", c.Title.From, m.Title))
+	}
+	switch {
+	case c.DueOn != nil && mep.Action == "edited":
+		lines = append(lines, fmt.Sprintf("Due: %s → %s", dueDate(c.DueOn.From), dueDate(m.DueOn)))
+	case m.DueOn != nil:
+		lines = append(lines, fmt.Sprintf("Due: %s", dueDate(m.DueOn)))
+	}
+
+	if total := m.OpenIssues + m.ClosedIssues; total > 0 && mep.Action != "deleted" {
+		lines = append(lines, fmt.Sprintf("Issues: %d open, %d closed (%d%% complete)", m.OpenIssues, m.ClosedIssues, m.ClosedIssues*100/total))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// dueDate renders a milestone due date, which has no meaningful time
+func dueDate(due *time.Time) string {
+	if due == nil {
+		return "none"
+	}
+	return due.Format("Jan 2, 2006")
+}
+
+// Username returns the username of the user who triggered the event
+func (mep *MilestoneEventPayload) Username() string {
+	return mep.Sender.Login
+}
+
+func (mep *MilestoneEventPayload) Repository() string {
+	return mep.Repo.Name
+}
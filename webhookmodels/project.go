@@ -0,0 +1,29 @@
+package webhookmodels
+
+import (
+	"fmt"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// ProjectContent is the issue, pull request or draft issue behind a project
+// card or item.  It's looked up on the API; payloads only link to it.
+type ProjectContent struct {
+	Number      int
+	Title       string
+	URL         string
+	PullRequest bool
+}
+
+// link renders the content's kind, number and title, linked when it has a
+// page of its own.
+func (pc *ProjectContent) link() string {
+	if len(pc.URL) < 1 {
+		return fmt.Sprintf("Draft: %s", pc.Title)
+	}
+	kind := "Issue"
+	if pc.PullRequest {
+		kind = "Pull request"
+	}
+	return markdown.MarkdownLink(pc.URL, fmt.Sprintf("%s #%d: %s", kind, pc.Number, pc.Title))
+}
@@ -1,21 +1,61 @@
 package webhookmodels
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
 
 // ProjectCardEventPayload is the request received when a project card is created,
 // edited, moved, converted to an issue, or deleted.
 //
 // https://developer.github.com/v3/activity/events/types/#projectcardevent
 type ProjectCardEventPayload struct {
-	Action string     `json:"action"  binding:"required"`
-	Card   Card       `json:"project_card"`
+	Action  string `json:"action"  binding:"required"`
+	Card    Card   `json:"project_card"`
+	Changes struct {
+		ColumnID *struct {
+			From int64 `json:"from"`
+		} `json:"column_id"`
+		Note *ChangedValue `json:"note"`
+	} `json:"changes"`
 	Repo   Repository `json:"repository"`
 	Sender User       `json:"sender"`
+	// Column and PreviousColumn are the names of the card's column and,
+	// for moves, the column it came from.  Content is the card's issue or
+	// pull request.  They're looked up on the API.
+	Column         string          `json:"-"`
+	PreviousColumn string          `json:"-"`
+	Content        *ProjectContent `json:"-"`
 }
 
 // ToString outputs a summary message of the event
 func (pcep *ProjectCardEventPayload) ToString() string {
-	return fmt.Sprintf("%v a card: \n----\nNote: %v", pcep.Action, pcep.Card.Note)
+	lines := []string{markdown.MarkdownBold(fmt.Sprintf("%v a card", pcep.Action))}
+
+	if pcep.Content != nil {
+		lines = append(lines, pcep.Content.link())
+	} else if note := strings.TrimSpace(pcep.Card.Note); len(note) > 0 {
+		lines = append(lines, quote(markdown.ToSlack(note)))
+	}
+
+	switch {
+	case len(pcep.PreviousColumn) > 0 && len(pcep.Column) > 0 && pcep.PreviousColumn != pcep.Column:
+		lines = append(lines, fmt.Sprintf("Column: %s → %s", pcep.PreviousColumn, pcep.Column))
+	case len(pcep.Column) > 0:
+		lines = append(lines, fmt.Sprintf("Column: %s", pcep.Column))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// MovedFrom returns the ID of the column a moved card came from, or 0
+func (pcep *ProjectCardEventPayload) MovedFrom() int64 {
+	if pcep.Changes.ColumnID == nil {
+		return 0
+	}
+	return pcep.Changes.ColumnID.From
 }
 
 // Username returns the username of the user who triggered the event
@@ -0,0 +1,114 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// ProjectsV2ItemEventPayload is the request received when an item on an
+// organization's project board is created, edited, deleted, archived,
+// restored, converted, or reordered.  These hooks belong to the
+// organization, so the watcher's repo is the organization's login.
+//
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#projects_v2_item
+type ProjectsV2ItemEventPayload struct {
+	Action  string         `json:"action" binding:"required"`
+	Item    ProjectsV2Item `json:"projects_v2_item"`
+	Changes struct {
+		FieldValue *FieldValueChange `json:"field_value"`
+	} `json:"changes"`
+	Organization User `json:"organization"`
+	Sender       User `json:"sender"`
+	// Content is the item's issue, pull request or draft issue, looked up
+	// on the API.
+	Content *ProjectContent `json:"-"`
+}
+
+// ProjectsV2Item is an item on a project board
+type ProjectsV2Item struct {
+	ID            int64  `json:"id"`
+	NodeID        string `json:"node_id"`
+	ProjectNodeID string `json:"project_node_id"`
+	ContentNodeID string `json:"content_node_id"`
+	ContentType   string `json:"content_type"`
+}
+
+// FieldValueChange is a project field that was set on an item.  From and To
+// are strings, numbers, or objects for single select options and
+// iterations; older payloads leave them out.
+type FieldValueChange struct {
+	FieldName     string      `json:"field_name"`
+	FieldType     string      `json:"field_type"`
+	ProjectNumber int         `json:"project_number"`
+	From          interface{} `json:"from"`
+	To            interface{} `json:"to"`
+}
+
+// ToString outputs a summary message of the event.  Reordering items within
+// a column isn't announced.
+func (pv2iep *ProjectsV2ItemEventPayload) ToString() string {
+	var header string
+	switch pv2iep.Action {
+	case "reordered":
+		return ""
+	case "created":
+		header = "added an item to a project"
+	case "edited":
+		header = "updated a project item"
+	default:
+		header = fmt.Sprintf("%s a project item", pv2iep.Action)
+	}
+
+	lines := []string{markdown.MarkdownBold(header)}
+	if pv2iep.Content != nil {
+		lines = append(lines, pv2iep.Content.link())
+	} else if len(pv2iep.Item.ContentType) > 0 {
+		lines = append(lines, fmt.Sprintf("Item: %s", pv2iep.Item.ContentType))
+	}
+
+	if fv := pv2iep.Changes.FieldValue; fv != nil {
+		if fv.ProjectNumber > 0 {
+			lines = append(lines, fmt.Sprintf("Project: #%d", fv.ProjectNumber))
+		}
+		if fv.From != nil || fv.To != nil {
+			lines = append(lines, fmt.Sprintf("%s: %s → %s", fv.FieldName, fieldValue(fv.From), fieldValue(fv.To)))
+		} else {
+			lines = append(lines, fmt.Sprintf("Changed: %s", fv.FieldName))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// fieldValue renders a project field's value: the option name of a single
+// select, the title of an iteration, or the value itself.
+func fieldValue(value interface{}) string {
+	if v, ok := value.(map[string]interface{}); ok {
+		for _, key := range []string{"name", "title"} {
+			if s, ok := v[key].(string); ok {
+				return s
+			}
+		}
+	}
+	if s, ok := value.(string); ok && len(s) > 10 && s[4] == '-' && s[10] == 'T' {
+		// dates come through as midnight timestamps
+		return s[:10]
+	}
+	if value == nil || value == "" {
+		return "none"
+	}
+	return fmt.Sprint(value)
+}
+
+// Username returns the username of the user who triggered the event
+func (pv2iep *ProjectsV2ItemEventPayload) Username() string {
+	return pv2iep.Sender.Login
+}
+
+// Repository returns the organization's login, since project boards aren't
+// tied to one repo
+func (pv2iep *ProjectsV2ItemEventPayload) Repository() string {
+	return pv2iep.Organization.Login
+}
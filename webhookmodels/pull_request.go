@@ -14,11 +14,22 @@ type PullRequest struct {
 	Body   string `json:"body"`
 	Head   struct {
 		Branch string `json:"ref"`
+		SHA    string `json:"sha"`
 	} `json:"head"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	Assignee  User       `json:"assignee"`
-	Labels    Labels     `json:"labels"`
-	Repo      Repository `json:"repository"`
-	Sender    User       `json:"sender"`
+	Base struct {
+		Branch string `json:"ref"`
+	} `json:"base"`
+	Draft          bool       `json:"draft"`
+	Merged         bool       `json:"merged"`
+	MergedBy       User       `json:"merged_by"`
+	MergeCommitSHA string     `json:"merge_commit_sha"`
+	Commits        int        `json:"commits"`
+	Additions      int        `json:"additions"`
+	Deletions      int        `json:"deletions"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	Assignee       User       `json:"assignee"`
+	Labels         Labels     `json:"labels"`
+	Repo           Repository `json:"repository"`
+	Sender         User       `json:"sender"`
 }
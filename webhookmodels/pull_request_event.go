@@ -17,28 +17,89 @@ type PullRequestEventPayload struct {
 	PullRequest PullRequest `json:"pull_request"`
 	Repo        Repository  `json:"repository"`
 	Sender      User        `json:"sender"`
-	Merged      bool        `json:"merged"`
-	Additions   int         `json:"additions"`
-	Deletions   int         `json:"deletions"`
+	Changes     Changes     `json:"changes"`
+	// Assignee is who was assigned or unassigned
+	Assignee User `json:"assignee"`
+	// RequestedReviewer or RequestedTeam is who a review was requested
+	// from, or who the request was removed for
+	RequestedReviewer User `json:"requested_reviewer"`
+	RequestedTeam     struct {
+		Name string `json:"name"`
+		Slug string `json:"slug"`
+	} `json:"requested_team"`
+	// Before and After are the old and new head SHAs on synchronize
+	Before string `json:"before"`
+	After  string `json:"after"`
 }
 
 // ToString outputs a summary message of the event
 func (prep *PullRequestEventPayload) ToString() string {
+	pr := prep.PullRequest
 	header := markdown.MarkdownBold(fmt.Sprintf("%v a pull request", prep.Action))
-	title := markdown.MarkdownLink(prep.PullRequest.URL, fmt.Sprintf("Title: %s", prep.PullRequest.Title))
-	body := markdown.MarkdownMultilineCode(prep.PullRequest.Body)
-	if prep.Action == "opened" || prep.Action == "edited" {
+	title := markdown.MarkdownLink(pr.URL, fmt.Sprintf("Title: %s", pr.Title))
+	body := markdown.MarkdownMultilineCode(pr.Body)
+
+	switch prep.Action {
+	case "opened":
 		return fmt.Sprintf("%s\n%s\n%s", header, title, body)
-	} else if prep.Action == "labeled" {
-		labels := markdown.MarkdownMultilineCode(markdown.MarkdownList(prep.PullRequest.Labels.Names()))
+	case "edited":
+		return editMessage(header, title, &prep.Changes, pr.Title, pr.Body)
+	case "labeled":
+		labels := markdown.MarkdownMultilineCode(markdown.MarkdownList(pr.Labels.Names()))
 		return fmt.Sprintf("%s\n%s\nLabels:\n%s", header, title, labels)
-	} else if prep.Action == "closed" {
+	case "closed":
+		if !pr.Merged {
+			return fmt.Sprintf("%s\n%s", header, title)
+		}
+		header = markdown.MarkdownBold(fmt.Sprintf("merged a pull request into %s", pr.Base.Branch))
+		if len(pr.MergeCommitSHA) < 1 {
+			return fmt.Sprintf("%s\n%s", header, title)
+		}
+		commit := markdown.MarkdownCode(shortSHA(pr.MergeCommitSHA))
+		if len(prep.Repo.URL) > 0 {
+			commit = markdown.MarkdownLink(fmt.Sprintf("%s/commit/%s", prep.Repo.URL, pr.MergeCommitSHA), commit)
+		}
+		return fmt.Sprintf("%s\n%s\nMerge commit: %s", header, title, commit)
+	case "reopened":
+		return fmt.Sprintf("%s\n%s", header, title)
+	case "review_requested":
+		header = markdown.MarkdownBold(fmt.Sprintf("requested a review from %s", prep.reviewer()))
+		return fmt.Sprintf("%s\n%s", header, title)
+	case "review_request_removed":
+		header = markdown.MarkdownBold(fmt.Sprintf("removed the review request for %s", prep.reviewer()))
+		return fmt.Sprintf("%s\n%s", header, title)
+	case "assigned":
+		header = markdown.MarkdownBold(fmt.Sprintf("assigned %s to a pull request", prep.Assignee.Login))
+		return fmt.Sprintf("%s\n%s", header, title)
+	case "unassigned":
+		header = markdown.MarkdownBold(fmt.Sprintf("unassigned %s from a pull request", prep.Assignee.Login))
+		return fmt.Sprintf("%s\n%s", header, title)
+	case "synchronize":
+		header = markdown.MarkdownBold("pushed new commits to a pull request")
+		if len(prep.Repo.URL) < 1 || len(prep.Before) < 1 {
+			return fmt.Sprintf("%s\n%s", header, title)
+		}
+		compare := fmt.Sprintf("%s/compare/%s...%s", prep.Repo.URL, prep.Before, prep.After)
+		return fmt.Sprintf("%s\n%s\n%s", header, title, markdown.MarkdownLink(compare, "Compare"))
+	case "ready_for_review":
+		header = markdown.MarkdownBold("marked a pull request ready for review")
+		return fmt.Sprintf("%s\n%s", header, title)
+	case "converted_to_draft":
+		header = markdown.MarkdownBold("converted a pull request to a draft")
 		return fmt.Sprintf("%s\n%s", header, title)
 	}
 
 	return ""
 }
 
+// reviewer names the user or team a review was requested from.
+func (prep *PullRequestEventPayload) reviewer() string {
+	if len(prep.RequestedTeam.Name) > 0 {
+		return fmt.Sprintf("the %s team", prep.RequestedTeam.Name)
+	}
+	return prep.RequestedReviewer.Login
+}
+
 // Username returns the username of the user who triggered the event
 func (prep *PullRequestEventPayload) Username() string {
 	return prep.Sender.Login
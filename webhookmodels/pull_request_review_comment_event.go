@@ -1,8 +1,11 @@
 package webhookmodels
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
 
-import "github.com/mike-webster/repo-watcher/markdown"
+	"github.com/mike-webster/repo-watcher/markdown"
+)
 
 // PullRequestReviewCommentEventPayload is the request received when a comment
 // on a pull request's unified dif is created, edited, or deleted.
@@ -12,16 +15,44 @@ type PullRequestReviewCommentEventPayload struct {
 	Action      string        `json:"action" binding:"required"`
 	PullRequest PullRequest   `json:"pull_request"`
 	Comment     ReviewComment `json:"comment"`
+	Changes     Changes       `json:"changes"`
 	Repo        Repository    `json:"repository"`
 	Sender      User          `json:"sender"`
+	// Replies are later comments in the same conversation that arrived
+	// soon enough to be announced with this one.
+	Replies []ReviewComment `json:"-"`
 }
 
 // ToString outputs a summary message of the event
 func (prrcep *PullRequestReviewCommentEventPayload) ToString() string {
 	header := markdown.MarkdownBold(fmt.Sprintf("%s a comment on a pull request review", prrcep.Action))
 	title := markdown.MarkdownLink(prrcep.PullRequest.URL, fmt.Sprintf("Title:  %s", prrcep.PullRequest.Title))
-	comment := markdown.MarkdownMultilineCode(prrcep.Comment.Body)
-	return fmt.Sprintf("%s\n%s\n%s", header, title, comment)
+	if prrcep.Action == "edited" {
+		return editMessage(header, title, &prrcep.Changes, "", prrcep.Comment.Body)
+	}
+
+	c := prrcep.Comment
+	if c.InReplyToID > 0 {
+		header = markdown.MarkdownBold("replied to a comment on a pull request review")
+	}
+	lines := []string{header, title, markdown.MarkdownLink(c.URL, markdown.MarkdownCode(c.Location()))}
+	if hunk := c.Hunk(); len(hunk) > 0 {
+		lines = append(lines, markdown.MarkdownMultilineCode(hunk))
+	}
+
+	if len(prrcep.Replies) < 1 {
+		return strings.Join(append(lines, quote(markdown.ToSlack(c.Body))), "\n")
+	}
+
+	for _, r := range append([]ReviewComment{c}, prrcep.Replies...) {
+		lines = append(lines, quote(fmt.Sprintf("%s: %s", markdown.MarkdownBold(r.User.Login), markdown.ToSlack(r.Body))))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ThreadKey identifies the conversation the comment belongs to.
+func (prrcep *PullRequestReviewCommentEventPayload) ThreadKey() string {
+	return fmt.Sprintf("%s#%d/%d", prrcep.Repo.Slug(), prrcep.PullRequest.Number, prrcep.Comment.ThreadID())
 }
 
 // Username returns the username of the user who triggered the event
@@ -32,3 +63,12 @@ func (prrcep *PullRequestReviewCommentEventPayload) Username() string {
 func (prrcep *PullRequestReviewCommentEventPayload) Repository() string {
 	return prrcep.Repo.Name
 }
+
+// quote renders every line of the text as a slack quote
+func quote(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, l := range lines {
+		lines[i] = markdown.MarkdownQuote(l)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -1,8 +1,19 @@
 package webhookmodels
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
 
-import "github.com/mike-webster/repo-watcher/markdown"
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// Review states as they're sent in webhooks
+const (
+	ReviewApproved         = "approved"
+	ReviewChangesRequested = "changes_requested"
+	ReviewCommented        = "commented"
+	ReviewDismissed        = "dismissed"
+)
 
 // PullRequestReviewEventPayload is the request received when a pull request review
 // is submitted into a non-pending state, the body is edited, or the review is dismissed.
@@ -14,15 +25,66 @@ type PullRequestReviewEventPayload struct {
 	Review      Review      `json:"review"`
 	Repo        Repository  `json:"repository"`
 	Sender      User        `json:"sender"`
+	// Tally counts each reviewer's latest review on the pull request,
+	// including this one.
+	Tally *ReviewTally `json:"-"`
+}
+
+// ReviewTally counts where a pull request's reviews stand
+type ReviewTally struct {
+	Approvals      int
+	ChangeRequests int
 }
 
-// ToString outputs a summary message of the event
+// ToString summarizes the tally, e.g. "2 approvals, 1 change request"
+func (rt *ReviewTally) ToString() string {
+	parts := []string{
+		plural(rt.Approvals, "approval", "approvals"),
+		plural(rt.ChangeRequests, "change request", "change requests"),
+	}
+	summary := strings.Join(parts, ", ")
+	if rt.Approvals > 0 && rt.ChangeRequests < 1 {
+		summary += " — ready to merge?"
+	}
+	return summary
+}
+
+// ToString outputs a summary message of the event.  Comment-only reviews
+// without a body are skipped; their review comments are announced instead.
 func (prrep *PullRequestReviewEventPayload) ToString() string {
-	header := markdown.MarkdownBold(fmt.Sprintf("%s a pull request review", prrep.Action))
-	title := markdown.MarkdownLink(prrep.PullRequest.URL, fmt.Sprintf("Title: %s", prrep.PullRequest.Title))
-	state := fmt.Sprintf("State: %s", prrep.Review.State)
-	body := markdown.MarkdownMultilineCode(fmt.Sprintf("Body: \n%v", prrep.PullRequest.Body))
-	return fmt.Sprintf("%s\n%s\n%s\n%s", header, title, state, body)
+	state := strings.ToLower(prrep.Review.State)
+	var header string
+	switch {
+	case prrep.Action == "dismissed":
+		header = fmt.Sprintf("dismissed %s's review", prrep.Review.User.Login)
+	case prrep.Action != "submitted":
+		return ""
+	case state == ReviewApproved:
+		header = "approved a pull request"
+	case state == ReviewChangesRequested:
+		header = "requested changes on a pull request"
+	case state == ReviewCommented && len(strings.TrimSpace(prrep.Review.Body)) > 0:
+		header = "reviewed a pull request"
+	default:
+		return ""
+	}
+
+	url := prrep.Review.URL
+	if len(url) < 1 {
+		url = prrep.PullRequest.URL
+	}
+	lines := []string{
+		markdown.MarkdownBold(header),
+		markdown.MarkdownLink(url, fmt.Sprintf("Title: %s", prrep.PullRequest.Title)),
+	}
+	if body := strings.TrimSpace(prrep.Review.Body); len(body) > 0 && prrep.Action == "submitted" {
+		lines = append(lines, quote(markdown.ToSlack(body)))
+	}
+	if prrep.Tally != nil {
+		lines = append(lines, markdown.MarkdownItalic(prrep.Tally.ToString()))
+	}
+
+	return strings.Join(lines, "\n")
 }
 
 // Username returns the username of the user who triggered the event
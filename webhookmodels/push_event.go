@@ -1,9 +1,7 @@
 package webhookmodels
 
 import (
-	"errors"
 	"fmt"
-	"reflect"
 	"strings"
 
 	"github.com/mike-webster/repo-watcher/markdown"
@@ -13,51 +11,116 @@ import (
 //
 // https://developer.github.com/v3/activity/events/types/#pushevent
 type PushEventPayload struct {
-	Ref     string        `json:"ref" binding:"required"`
-	Commits []interface{} `json:"commits"`
-	Repo    Repository    `json:"repository"`
-	URL     string        `json:"compare"`
-	Sender  User          `json:"sender"`
+	Ref     string   `json:"ref" binding:"required"`
+	Before  string   `json:"before"`
+	After   string   `json:"after"`
+	Created bool     `json:"created"`
+	Deleted bool     `json:"deleted"`
+	Forced  bool     `json:"forced"`
+	Commits []Commit `json:"commits"`
+	// Size is how many commits were pushed, which can be more than are
+	// listed.  Payloads that don't carry it have it filled in from the
+	// compare API when the list was cut short.
+	Size   int          `json:"size"`
+	Repo   Repository   `json:"repository"`
+	URL    string       `json:"compare"`
+	Sender User         `json:"sender"`
+	Pusher CommitAuthor `json:"pusher"`
+	// CommitLimit caps how many commits are listed; the rest are counted.
+	CommitLimit int `json:"-"`
+	// Protected is set when the watcher protects the ref, so rewriting or
+	// deleting it is announced as an alert.
+	Protected bool `json:"-"`
 }
 
-// CommitMessages returns a formatted list of strings from all commits
-// in the push
-func (pep *PushEventPayload) CommitMessages() (string, *[]error) {
-	ret := []string{}
-	errs := []error{}
-	for _, c := range pep.Commits {
-		body, ok := c.(map[string]interface{})
-		if !ok {
-			errs = append(errs, errors.New("couldn't parse commits"))
-			continue
+// DefaultCommitLimit is how many commits are listed when CommitLimit isn't
+// set.
+const DefaultCommitLimit = 10
+
+// PayloadCommitCap is the most commits GitHub lists in a push payload.
+const PayloadCommitCap = 20
+
+// ToString outputs a summary message of the event.  Deleted refs are
+// announced by the delete event, unless they were protected.
+func (pep *PushEventPayload) ToString() string {
+	if pep.Protected && (pep.Forced || pep.Deleted) {
+		return pep.alert()
+	}
+	if pep.Deleted {
+		return ""
+	}
+
+	verb := "pushed"
+	if pep.Forced {
+		verb = "force-pushed"
+	}
+	header := markdown.MarkdownLink(pep.URL, fmt.Sprintf("%s some changes to %s", verb, pep.Ref))
+	title := markdown.MarkdownItalic("Commits:")
+	return fmt.Sprintf("%s\n%s\n%s", header, title, pep.commitList())
+}
+
+// commitList renders a line per commit, up to the commit limit.
+func (pep *PushEventPayload) commitList() string {
+	limit := pep.CommitLimit
+	if limit < 1 {
+		limit = DefaultCommitLimit
+	}
+
+	lines := []string{}
+	for i, c := range pep.Commits {
+		if i == limit {
+			break
 		}
 
-		message, ok := body["message"].(string)
-		if !ok {
-			errs = append(errs, errors.New(fmt.Sprint("bad conversion: ", reflect.TypeOf(c))))
-			continue
+		line := fmt.Sprintf("%s %s", markdown.MarkdownLink(c.URL, markdown.MarkdownCode(shortSHA(c.ID))), c.Title())
+		if !pep.pushedBy(c.Author) {
+			line = fmt.Sprintf("%s - %s", line, c.Author.Name)
 		}
-		ret = append(ret, fmt.Sprint(message))
+		lines = append(lines, line)
 	}
-	if len(errs) < 1 {
-		return strings.Join(ret, "\n"), nil
+
+	total := pep.Size
+	if total < len(pep.Commits) {
+		total = len(pep.Commits)
+	}
+	if pep.Size < 1 && len(pep.Commits) >= PayloadCommitCap {
+		// the list may have been cut short and there's no telling by how much
+		lines = append(lines, markdown.MarkdownItalic(markdown.MarkdownLink(pep.URL, "and more — see compare")))
+	} else if total > len(lines) {
+		lines = append(lines, markdown.MarkdownItalic(fmt.Sprintf("and %d more", total-len(lines))))
 	}
 
-	return strings.Join(ret, "\n"), &errs
+	return strings.Join(lines, "\n")
 }
 
-// ToString outputs a summary message of the event
-func (pep *PushEventPayload) ToString() string {
-	messages, errs := pep.CommitMessages()
-	if errs != nil {
-		for _, m := range *errs {
-			fmt.Println("commit message parse error: ", m.Error())
-		}
+// pushedBy returns true if the author is the one who pushed
+func (pep *PushEventPayload) pushedBy(author CommitAuthor) bool {
+	if len(author.Username) > 0 && strings.EqualFold(author.Username, pep.Sender.Login) {
+		return true
 	}
-	header := markdown.MarkdownLink(pep.URL, fmt.Sprintf("pushed some changes to %s", pep.Ref))
-	title := markdown.MarkdownItalic("Commits:")
-	body := markdown.MarkdownMultilineCode(messages)
-	return fmt.Sprintf("%s\n%s\n%s", header, title, body)
+	if len(author.Email) > 0 && strings.EqualFold(author.Email, pep.Pusher.Email) {
+		return true
+	}
+	return len(author.Name) < 1 || author.Name == pep.Pusher.Name
+}
+
+// alert renders a rewrite or deletion of a protected ref loudly enough
+// that it won't be missed.
+func (pep *PushEventPayload) alert() string {
+	what := "force-pushed"
+	if pep.Deleted {
+		what = "deleted"
+	}
+
+	lines := []string{
+		fmt.Sprintf("<!here> :rotating_light: %s", markdown.MarkdownBold(fmt.Sprintf("%s protected ref %s", what, ShortRef(pep.Ref)))),
+		fmt.Sprintf("Before: %s", markdown.MarkdownCode(pep.Before)),
+		fmt.Sprintf("After: %s", markdown.MarkdownCode(pep.After)),
+	}
+	if len(pep.URL) > 0 {
+		lines = append(lines, markdown.MarkdownLink(pep.URL, "Compare"))
+	}
+	return strings.Join(lines, "\n")
 }
 
 // Username returns the username of the user who triggered the event
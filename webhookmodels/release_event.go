@@ -0,0 +1,108 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// ReleaseEventPayload is the request received when a release is published,
+// unpublished, created, edited, deleted or prereleased.
+//
+// https://developer.github.com/v3/activity/events/types/#releaseevent
+type ReleaseEventPayload struct {
+	Action  string     `json:"action" binding:"required"`
+	Release Release    `json:"release"`
+	Repo    Repository `json:"repository"`
+	Sender  User       `json:"sender"`
+	// Changelog lists the pull requests merged since the previous release.
+	// It's looked up when the release has no body of its own.
+	Changelog []ChangelogEntry `json:"-"`
+}
+
+// Release represents a github release
+type Release struct {
+	ID         int64  `json:"id"`
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	Body       string `json:"body"`
+	URL        string `json:"html_url"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+	Author     User   `json:"author"`
+}
+
+// ChangelogEntry is a pull request that went out in a release
+type ChangelogEntry struct {
+	Number int
+	Title  string
+}
+
+// Status describes whether the release is a draft or a prerelease.
+func (r *Release) Status() string {
+	switch {
+	case r.Draft:
+		return "draft"
+	case r.Prerelease:
+		return "prerelease"
+	}
+	return ""
+}
+
+// ToString outputs a summary message of the event.  Only newly published
+// releases and new drafts are announced; the other actions arrive alongside
+// those and would just repeat them.
+func (rep *ReleaseEventPayload) ToString() string {
+	if rep.Action != "published" && !(rep.Action == "created" && rep.Release.Draft) {
+		return ""
+	}
+
+	name := rep.Release.Name
+	if len(name) < 1 {
+		name = rep.Release.TagName
+	}
+
+	header := markdown.MarkdownBold(fmt.Sprintf("%v a release", rep.Action))
+	link := markdown.MarkdownBold(name)
+	if len(rep.Release.URL) > 0 {
+		link = markdown.MarkdownLink(rep.Release.URL, name)
+	}
+	title := fmt.Sprintf("%s %s", link, markdown.MarkdownCode(rep.Release.TagName))
+	if status := rep.Release.Status(); len(status) > 0 {
+		title = fmt.Sprintf("%s %s", title, markdown.MarkdownItalic(status))
+	}
+	lines := []string{header, title}
+	if len(rep.Release.Author.Login) > 0 {
+		lines = append(lines, fmt.Sprint("Author: ", rep.Release.Author.Login))
+	}
+
+	if body := strings.TrimSpace(rep.Release.Body); len(body) > 0 {
+		lines = append(lines, markdown.ToSlack(body))
+	} else if len(rep.Changelog) > 0 {
+		lines = append(lines, markdown.MarkdownItalic("Changes:"), rep.changelog())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (rep *ReleaseEventPayload) changelog() string {
+	items := []string{}
+	for _, e := range rep.Changelog {
+		number := fmt.Sprint("#", e.Number)
+		if len(rep.Repo.URL) > 0 {
+			number = markdown.MarkdownLink(fmt.Sprintf("%s/pull/%d", rep.Repo.URL, e.Number), number)
+		}
+		items = append(items, fmt.Sprintf("%s %s", number, e.Title))
+	}
+	return strings.TrimSuffix(markdown.MarkdownList(items), "\n")
+}
+
+// Username returns the username of the user who triggered the event
+func (rep *ReleaseEventPayload) Username() string {
+	return rep.Sender.Login
+}
+
+func (rep *ReleaseEventPayload) Repository() string {
+	return rep.Repo.Name
+}
@@ -2,11 +2,22 @@ package webhookmodels
 
 // Repository represents a github repository
 type Repository struct {
-	ID          int64  `json:"id"`
-	NodeID      string `json:"node_id"`
-	Name        string `json:"name"`
-	Owner       User   `json:"owner"`
-	Sender      User   `json:"sender"`
-	URL         string `json:"html_url"`
-	Description string `json:"description"`
+	ID            int64  `json:"id"`
+	NodeID        string `json:"node_id"`
+	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
+	Owner         User   `json:"owner"`
+	Sender        User   `json:"sender"`
+	URL           string `json:"html_url"`
+	Description   string `json:"description"`
+	Homepage      string `json:"homepage"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+// Slug returns the owner/name path the API knows the repo by.
+func (r *Repository) Slug() string {
+	if len(r.FullName) > 0 {
+		return r.FullName
+	}
+	return r.Owner.Login + "/" + r.Name
 }
@@ -0,0 +1,100 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// RepositoryEventPayload is the request received when a repository is
+// created, deleted, archived, unarchived, publicized, privatized, edited,
+// renamed, or transferred.
+//
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#repository
+type RepositoryEventPayload struct {
+	Action  string `json:"action" binding:"required"`
+	Changes struct {
+		DefaultBranch *ChangedValue `json:"default_branch"`
+		Description   *ChangedValue `json:"description"`
+		Homepage      *ChangedValue `json:"homepage"`
+		Repository    *struct {
+			Name ChangedValue `json:"name"`
+		} `json:"repository"`
+		Owner *struct {
+			From struct {
+				User         *User `json:"user"`
+				Organization *User `json:"organization"`
+			} `json:"from"`
+		} `json:"owner"`
+	} `json:"changes"`
+	Repo   Repository `json:"repository"`
+	Sender User       `json:"sender"`
+}
+
+// ToString outputs a summary message of the event
+func (rep *RepositoryEventPayload) ToString() string {
+	var header string
+	var changes []string
+	switch rep.Action {
+	case "publicized":
+		header = ":warning: made a repository public"
+		changes = append(changes, "Visibility: private → public")
+	case "privatized":
+		header = "made a repository private"
+		changes = append(changes, "Visibility: public → private")
+	case "renamed":
+		header = "renamed a repository"
+		if rep.Changes.Repository != nil {
+			changes = append(changes, fmt.Sprintf("Name: %s → %s", rep.Changes.Repository.Name.From, rep.Repo.Name))
+		}
+	case "transferred":
+		header = "transferred a repository"
+		if o := rep.Changes.Owner; o != nil {
+			from := o.From.Organization
+			if from == nil {
+				from = o.From.User
+			}
+			if from != nil {
+				changes = append(changes, fmt.Sprintf("Owner: %s → %s", from.Login, rep.Repo.Owner.Login))
+			}
+		}
+	case "edited":
+		header = "changed a repository's settings"
+		c := &rep.Changes
+		if c.DefaultBranch != nil {
+			changes = append(changes, fmt.Sprintf("Default branch: %s → %s", c.DefaultBranch.From, rep.Repo.DefaultBranch))
+		}
+		if c.Description != nil {
+			changes = append(changes, fmt.Sprintf("Description: %s → %s", orNone(c.Description.From), orNone(rep.Repo.Description)))
+		}
+		if c.Homepage != nil {
+			changes = append(changes, fmt.Sprintf("Homepage: %s → %s", orNone(c.Homepage.From), orNone(rep.Repo.Homepage)))
+		}
+	default:
+		header = fmt.Sprintf("%s a repository", rep.Action)
+	}
+
+	lines := []string{
+		markdown.MarkdownBold(header),
+		markdown.MarkdownLink(rep.Repo.URL, fmt.Sprintf("Repository: %s", rep.Repo.Slug())),
+	}
+	return strings.Join(append(lines, changes...), "\n")
+}
+
+// Audited marks the event for the audit channel
+func (rep *RepositoryEventPayload) Audited() {}
+
+// Username returns the username of the user who triggered the event
+func (rep *RepositoryEventPayload) Username() string {
+	return rep.Sender.Login
+}
+
+// Repository returns the name the repo is watched under, which is the old
+// one for a rename.
+func (rep *RepositoryEventPayload) Repository() string {
+	if rep.Action == "renamed" && rep.Changes.Repository != nil && len(rep.Changes.Repository.Name.From) > 0 {
+		return rep.Changes.Repository.Name.From
+	}
+	return rep.Repo.Name
+}
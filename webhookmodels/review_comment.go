@@ -1,6 +1,10 @@
 package webhookmodels
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // ReviewComment represents a user's comment on a pull request
 type ReviewComment struct {
@@ -8,6 +12,11 @@ type ReviewComment struct {
 	ReviewID       int64     `json:"pull_request_review_id"`
 	NodeID         string    `json:"node_id"`
 	Path           string    `json:"path"`
+	DiffHunk       string    `json:"diff_hunk"`
+	Line           int       `json:"line"`
+	OriginalLine   int       `json:"original_line"`
+	CommitID       string    `json:"commit_id"`
+	InReplyToID    int64     `json:"in_reply_to_id"`
 	URL            string    `json:"html_url"`
 	User           User      `json:"user"`
 	Body           string    `json:"body"`
@@ -15,3 +24,40 @@ type ReviewComment struct {
 	UpdatedAt      time.Time `json:"updated_at"`
 	PullRequestURL string    `json:"pull_request_url"`
 }
+
+// hunkLines is how much of the diff hunk is shown above a comment.
+const hunkLines = 5
+
+// ThreadID returns the ID of the comment that started the conversation.
+func (rc *ReviewComment) ThreadID() int64 {
+	if rc.InReplyToID > 0 {
+		return rc.InReplyToID
+	}
+	return rc.ID
+}
+
+// Location returns the file and line the comment is on.  Comments on lines
+// that have since changed use the line they were made on.
+func (rc *ReviewComment) Location() string {
+	line := rc.Line
+	if line < 1 {
+		line = rc.OriginalLine
+	}
+	if line < 1 {
+		return rc.Path
+	}
+	return fmt.Sprintf("%s:%d", rc.Path, line)
+}
+
+// Hunk returns the end of the diff hunk, which is the part the comment
+// is about.  The @@ header is dropped.
+func (rc *ReviewComment) Hunk() string {
+	lines := strings.Split(strings.TrimRight(rc.DiffHunk, "\n"), "\n")
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "@@") {
+		lines = lines[1:]
+	}
+	if len(lines) > hunkLines {
+		lines = lines[len(lines)-hunkLines:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,76 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// SecretScanningAlertEventPayload is the request received when a secret
+// scanning alert is created, resolved, reopened, revoked, validated, or
+// found leaked publicly.  The payload doesn't say where the secret was
+// found; the alert's page does.
+//
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#secret_scanning_alert
+type SecretScanningAlertEventPayload struct {
+	Action string              `json:"action" binding:"required"`
+	Alert  SecretScanningAlert `json:"alert"`
+	Repo   Repository          `json:"repository"`
+	Sender User                `json:"sender"`
+}
+
+// SecretScanningAlert is a credential committed to the repo
+type SecretScanningAlert struct {
+	Number                   int    `json:"number"`
+	State                    string `json:"state"`
+	URL                      string `json:"html_url"`
+	SecretType               string `json:"secret_type"`
+	SecretTypeDisplayName    string `json:"secret_type_display_name"`
+	Resolution               string `json:"resolution"`
+	ResolutionComment        string `json:"resolution_comment"`
+	Validity                 string `json:"validity"`
+	PushProtectionBypassed   bool   `json:"push_protection_bypassed"`
+	PushProtectionBypassedBy *User  `json:"push_protection_bypassed_by"`
+}
+
+// ToString outputs a summary message of the event
+func (ssaep *SecretScanningAlertEventPayload) ToString() string {
+	a := &ssaep.Alert
+	name := a.SecretTypeDisplayName
+	if len(name) < 1 {
+		name = a.SecretType
+	}
+
+	lines := []string{
+		markdown.MarkdownBold(alertHeader(ssaep.Action, "secret scanning")),
+		markdown.MarkdownLink(a.URL, fmt.Sprintf("#%d %s", a.Number, name)),
+		severityLine(ssaep.Severity()),
+	}
+	if len(a.Validity) > 0 && a.Validity != "unknown" {
+		lines = append(lines, fmt.Sprintf("Validity: %s", a.Validity))
+	}
+	if a.PushProtectionBypassed && a.PushProtectionBypassedBy != nil {
+		lines = append(lines, fmt.Sprintf("Push protection bypassed by %s", a.PushProtectionBypassedBy.Login))
+	}
+	if reason := dismissalLine(a.Resolution, a.ResolutionComment); len(reason) > 0 && ssaep.Action == "resolved" {
+		lines = append(lines, reason)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Severity is always critical; a leaked secret needs rotating whatever it's
+// for.
+func (ssaep *SecretScanningAlertEventPayload) Severity() string {
+	return SeverityCritical
+}
+
+// Username returns the username of the user who triggered the event
+func (ssaep *SecretScanningAlertEventPayload) Username() string {
+	return ssaep.Sender.Login
+}
+
+func (ssaep *SecretScanningAlertEventPayload) Repository() string {
+	return ssaep.Repo.Name
+}
@@ -0,0 +1,94 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Security alert severities, lowest first
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+var severityRanks = map[string]int{
+	SeverityLow:      1,
+	SeverityMedium:   2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
+
+var severityEmoji = map[string]string{
+	SeverityLow:      ":white_circle:",
+	SeverityMedium:   ":large_yellow_circle:",
+	SeverityHigh:     ":large_orange_circle:",
+	SeverityCritical: ":red_circle:",
+}
+
+// SecurityAlert is implemented by dependabot, code scanning and secret
+// scanning payloads so they can be filtered by severity and routed to a
+// security channel.
+type SecurityAlert interface {
+	Event
+	Severity() string
+}
+
+// normalizeSeverity maps the different severity scales to low, medium, high
+// and critical.  Unknown severities are treated as low.
+func normalizeSeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case SeverityCritical:
+		return SeverityCritical
+	case SeverityHigh, "error":
+		return SeverityHigh
+	case SeverityMedium, "moderate", "warning":
+		return SeverityMedium
+	}
+	return SeverityLow
+}
+
+// SeverityAtLeast returns true if the severity is as bad as min.  An empty
+// min allows every severity.
+func SeverityAtLeast(severity string, min string) bool {
+	if len(min) < 1 {
+		return true
+	}
+	return severityRanks[normalizeSeverity(severity)] >= severityRanks[normalizeSeverity(min)]
+}
+
+// alertHeader describes what happened to the alert, e.g. "dismissed a
+// dependabot alert"
+func alertHeader(action string, kind string) string {
+	verb := strings.TrimSuffix(action, "_by_user")
+	switch verb {
+	case "created":
+		verb = "opened"
+	case "appeared_in_branch":
+		return fmt.Sprintf("found a %s alert on another branch", kind)
+	case "publicly_leaked":
+		return fmt.Sprintf("found a %s alert leaked publicly", kind)
+	}
+	return fmt.Sprintf("%s a %s alert", strings.Replace(verb, "_", "-", -1), kind)
+}
+
+// severityLine renders the severity with a colored marker
+func severityLine(severity string) string {
+	severity = normalizeSeverity(severity)
+	return fmt.Sprintf("Severity: %s %s", severityEmoji[severity], severity)
+}
+
+// dismissalLine renders why an alert was dismissed, or an empty string if it
+// wasn't.
+func dismissalLine(reason string, comment string) string {
+	reason = strings.Replace(reason, "_", " ", -1)
+	comment = strings.TrimSpace(comment)
+	switch {
+	case len(reason) > 0 && len(comment) > 0:
+		return fmt.Sprintf("Reason: %s — %s", reason, comment)
+	case len(reason) > 0:
+		return fmt.Sprintf("Reason: %s", reason)
+	}
+	return ""
+}
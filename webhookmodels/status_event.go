@@ -0,0 +1,70 @@
+package webhookmodels
+
+// StatusEventPayload is the request received when the status of a commit
+// changes.
+//
+// https://developer.github.com/v3/activity/events/types/#statusevent
+type StatusEventPayload struct {
+	SHA         string `json:"sha" binding:"required"`
+	State       string `json:"state" binding:"required"`
+	Context     string `json:"context"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+	Branches    []struct {
+		Name string `json:"name"`
+	} `json:"branches"`
+	Commit struct {
+		URL string `json:"html_url"`
+	} `json:"commit"`
+	Repo     Repository  `json:"repository"`
+	Sender   User        `json:"sender"`
+	Recovery *CIRecovery `json:"-"`
+}
+
+// CIResult returns the status as a CI result.  An error is reported as a
+// failure.
+func (sep *StatusEventPayload) CIResult() CIResult {
+	state := sep.State
+	if state == "error" {
+		state = CIFailure
+	}
+
+	branches := []string{}
+	for _, b := range sep.Branches {
+		branches = append(branches, b.Name)
+	}
+
+	url := sep.TargetURL
+	if len(url) < 1 {
+		url = sep.Commit.URL
+	}
+
+	return CIResult{
+		Repo:          sep.Repo.Slug(),
+		DefaultBranch: sep.Repo.DefaultBranch,
+		Context:       sep.Context,
+		State:         state,
+		SHA:           sep.SHA,
+		Branches:      branches,
+		URL:           url,
+		Description:   sep.Description,
+	}
+}
+
+func (sep *StatusEventPayload) SetRecovery(r *CIRecovery) {
+	sep.Recovery = r
+}
+
+// ToString outputs a summary message of the event
+func (sep *StatusEventPayload) ToString() string {
+	return ciMessage(sep.CIResult(), sep.Recovery)
+}
+
+// Username returns the username of the user who triggered the event
+func (sep *StatusEventPayload) Username() string {
+	return sep.Sender.Login
+}
+
+func (sep *StatusEventPayload) Repository() string {
+	return sep.Repo.Name
+}
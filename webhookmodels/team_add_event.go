@@ -0,0 +1,53 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// TeamAddEventPayload is the request received when a team is added to a
+// repository.
+//
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#team_add
+type TeamAddEventPayload struct {
+	Team   Team       `json:"team"`
+	Repo   Repository `json:"repository"`
+	Sender User       `json:"sender"`
+}
+
+// Team represents a github team
+type Team struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Slug       string `json:"slug"`
+	URL        string `json:"html_url"`
+	Permission string `json:"permission"`
+}
+
+// ToString outputs a summary message of the event
+func (taep *TeamAddEventPayload) ToString() string {
+	lines := []string{
+		markdown.MarkdownBold("added a team to a repository"),
+		fmt.Sprintf("Repository: %s", taep.Repo.Slug()),
+		markdown.MarkdownLink(taep.Team.URL, fmt.Sprintf("Team: %s", taep.Team.Name)),
+	}
+	if len(taep.Team.Permission) > 0 {
+		lines = append(lines, fmt.Sprintf("Permission: %s", taep.Team.Permission))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Audited marks the event for the audit channel
+func (taep *TeamAddEventPayload) Audited() {}
+
+// Username returns the username of the user who triggered the event
+func (taep *TeamAddEventPayload) Username() string {
+	return taep.Sender.Login
+}
+
+func (taep *TeamAddEventPayload) Repository() string {
+	return taep.Repo.Name
+}
@@ -34,8 +34,9 @@ func ShouldDeployMaster(e *Event) (*PullRequestEventPayload, bool) {
 		return nil, false
 	}
 
-	if prep.Action != "closed" {
-		// we  don't want to deploy master on open, label, etc
+	if prep.Action != "closed" || !prep.PullRequest.Merged {
+		// we  don't want to deploy master on open, label, etc, or when a
+		// pull request is closed without being merged
 		return nil, false
 	}
 
@@ -47,3 +48,32 @@ func ShouldDeployMaster(e *Event) (*PullRequestEventPayload, bool) {
 
 	return prep, true
 }
+
+// shortSHA returns the abbreviated form of a commit sha
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// ShortRef strips the refs/heads/ or refs/tags/ prefix from a git ref
+func ShortRef(ref string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(ref, "refs/heads/"), "refs/tags/")
+}
+
+// plural formats the count with the singular or plural noun
+func plural(count int, singular string, plurals string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, singular)
+	}
+	return fmt.Sprintf("%d %s", count, plurals)
+}
+
+// orNone returns the text, or "none" when it's empty
+func orNone(text string) string {
+	if len(strings.TrimSpace(text)) < 1 {
+		return "none"
+	}
+	return text
+}
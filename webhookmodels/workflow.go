@@ -0,0 +1,51 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mike-webster/repo-watcher/markdown"
+)
+
+// WorkflowResult is a finished GitHub Actions run or job
+type WorkflowResult struct {
+	Workflow   string
+	Conclusion string
+	Duration   time.Duration
+}
+
+// WorkflowEvent is implemented by the payloads for GitHub Actions.
+type WorkflowEvent interface {
+	Event
+	WorkflowResult() WorkflowResult
+}
+
+// workflowMessage renders a finished run or job the same way for both
+// events.
+func workflowMessage(title string, conclusion string, duration time.Duration, actor string, branch string, url string) string {
+	header := markdown.MarkdownBold(fmt.Sprintf("%s: %s", title, conclusion))
+	lines := []string{header}
+	if len(branch) > 0 {
+		lines = append(lines, fmt.Sprint("Branch: ", markdown.MarkdownCode(branch)))
+	}
+	if len(actor) > 0 {
+		lines = append(lines, fmt.Sprint("Triggered by: ", actor))
+	}
+	if duration > 0 {
+		lines = append(lines, fmt.Sprint("Took: ", duration.Round(time.Second)))
+	}
+	if len(url) > 0 {
+		lines = append(lines, markdown.MarkdownLink(url, "View on GitHub"))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// elapsed returns the time between the two, or 0 if either is missing.
+func elapsed(start time.Time, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
@@ -0,0 +1,68 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"time"
+)
+
+// WorkflowJobEventPayload is the request received when a GitHub Actions job
+// is queued, waiting, in progress or completed.
+//
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#workflow_job
+type WorkflowJobEventPayload struct {
+	Action      string      `json:"action" binding:"required"`
+	WorkflowJob WorkflowJob `json:"workflow_job"`
+	Repo        Repository  `json:"repository"`
+	Sender      User        `json:"sender"`
+}
+
+// WorkflowJob represents a job within a workflow run
+type WorkflowJob struct {
+	ID           int64     `json:"id"`
+	RunID        int64     `json:"run_id"`
+	RunAttempt   int       `json:"run_attempt"`
+	Name         string    `json:"name"`
+	WorkflowName string    `json:"workflow_name"`
+	Status       string    `json:"status"`
+	Conclusion   string    `json:"conclusion"`
+	URL          string    `json:"html_url"`
+	HeadBranch   string    `json:"head_branch"`
+	HeadSHA      string    `json:"head_sha"`
+	StartedAt    time.Time `json:"started_at"`
+	CompletedAt  time.Time `json:"completed_at"`
+}
+
+// WorkflowResult returns the job's outcome for filtering.
+func (wjep *WorkflowJobEventPayload) WorkflowResult() WorkflowResult {
+	return WorkflowResult{
+		Workflow:   wjep.WorkflowJob.WorkflowName,
+		Conclusion: wjep.WorkflowJob.Conclusion,
+		Duration:   elapsed(wjep.WorkflowJob.StartedAt, wjep.WorkflowJob.CompletedAt),
+	}
+}
+
+// ToString outputs a summary message of the event.  Only completed jobs are
+// announced.
+func (wjep *WorkflowJobEventPayload) ToString() string {
+	if wjep.Action != "completed" {
+		return ""
+	}
+
+	wj := wjep.WorkflowJob
+	title := fmt.Sprintf("%s / %s (run %d", wj.WorkflowName, wj.Name, wj.RunID)
+	if wj.RunAttempt > 1 {
+		title = fmt.Sprintf("%s, attempt %d", title, wj.RunAttempt)
+	}
+	title += ")"
+
+	return workflowMessage(title, wj.Conclusion, wjep.WorkflowResult().Duration, wjep.Sender.Login, wj.HeadBranch, wj.URL)
+}
+
+// Username returns the username of the user who triggered the event
+func (wjep *WorkflowJobEventPayload) Username() string {
+	return wjep.Sender.Login
+}
+
+func (wjep *WorkflowJobEventPayload) Repository() string {
+	return wjep.Repo.Name
+}
@@ -0,0 +1,69 @@
+package webhookmodels
+
+import (
+	"fmt"
+	"time"
+)
+
+// WorkflowRunEventPayload is the request received when a GitHub Actions
+// workflow run is requested, in progress or completed.
+//
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#workflow_run
+type WorkflowRunEventPayload struct {
+	Action      string      `json:"action" binding:"required"`
+	WorkflowRun WorkflowRun `json:"workflow_run"`
+	Repo        Repository  `json:"repository"`
+	Sender      User        `json:"sender"`
+}
+
+// WorkflowRun represents a single run of a GitHub Actions workflow
+type WorkflowRun struct {
+	ID              int64     `json:"id"`
+	Name            string    `json:"name"`
+	RunNumber       int       `json:"run_number"`
+	Event           string    `json:"event"`
+	Status          string    `json:"status"`
+	Conclusion      string    `json:"conclusion"`
+	URL             string    `json:"html_url"`
+	HeadBranch      string    `json:"head_branch"`
+	HeadSHA         string    `json:"head_sha"`
+	RunStartedAt    time.Time `json:"run_started_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Actor           User      `json:"actor"`
+	TriggeringActor User      `json:"triggering_actor"`
+}
+
+// WorkflowResult returns the run's outcome for filtering.
+func (wrep *WorkflowRunEventPayload) WorkflowResult() WorkflowResult {
+	return WorkflowResult{
+		Workflow:   wrep.WorkflowRun.Name,
+		Conclusion: wrep.WorkflowRun.Conclusion,
+		Duration:   elapsed(wrep.WorkflowRun.RunStartedAt, wrep.WorkflowRun.UpdatedAt),
+	}
+}
+
+// ToString outputs a summary message of the event.  Only completed runs are
+// announced.
+func (wrep *WorkflowRunEventPayload) ToString() string {
+	if wrep.Action != "completed" {
+		return ""
+	}
+
+	wr := wrep.WorkflowRun
+	actor := wr.TriggeringActor.Login
+	if len(actor) < 1 {
+		actor = wr.Actor.Login
+	}
+	title := fmt.Sprintf("%s #%d", wr.Name, wr.RunNumber)
+
+	return workflowMessage(title, wr.Conclusion, wrep.WorkflowResult().Duration, actor, wr.HeadBranch, wr.URL)
+}
+
+// Username returns the username of the user who triggered the event
+func (wrep *WorkflowRunEventPayload) Username() string {
+	return wrep.Sender.Login
+}
+
+func (wrep *WorkflowRunEventPayload) Repository() string {
+	return wrep.Repo.Name
+}
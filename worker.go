@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	webhookmodels "github.com/mike-webster/repo-watcher/webhookmodels"
+	"github.com/sirupsen/logrus"
+)
+
+// job is a parsed webhook event waiting to be announced.
+type job struct {
+	ctx       context.Context
+	eventName string
+	event     webhookmodels.Event
+}
+
+// workerPool announces queued events in the background so a webhook can be
+// acknowledged before the name lookup and the Slack call have finished.
+type workerPool struct {
+	jobs    chan job
+	workers int
+	deps    *AppDependencies
+	threads *reviewThreads
+}
+
+func newWorkerPool(workers int, size int, threadWindow time.Duration, deps *AppDependencies) *workerPool {
+	if workers < 1 {
+		workers = defaultWorkers
+	}
+	if size < 1 {
+		size = defaultQueueSize
+	}
+
+	wp := &workerPool{
+		jobs:    make(chan job, size),
+		workers: workers,
+		deps:    deps,
+	}
+	wp.threads = newReviewThreads(threadWindow, deps.store, deps.logger, wp.announce)
+	return wp
+}
+
+// Start spins up the workers and picks up any review comments that were
+// still held when the process last stopped.  The workers run for the life
+// of the process.
+func (wp *workerPool) Start() {
+	for i := 0; i < wp.workers; i++ {
+		go func() {
+			for j := range wp.jobs {
+				wp.process(j)
+			}
+		}()
+	}
+
+	if err := wp.threads.Resume(); err != nil {
+		wp.deps.logger.WithFields(logrus.Fields{
+			"event": "failed_thread_resume",
+			"error": err,
+		}).Error("couldn't pick up held review comments")
+	}
+}
+
+// Enqueue adds the job to the queue without blocking.  It returns false when
+// the queue is full and the job was dropped.
+func (wp *workerPool) Enqueue(j job) bool {
+	select {
+	case wp.jobs <- j:
+		return true
+	default:
+		return false
+	}
+}
+
+// Depth returns the number of jobs waiting for a worker.
+func (wp *workerPool) Depth() int {
+	return len(wp.jobs)
+}
+
+func (wp *workerPool) process(j job) {
+	logger := wp.deps.logger
+	defer wp.recover(j)
+
+	if !allowEvent(wp.deps, j.event, logger) {
+		logger.WithFields(logrus.Fields{
+			"event":      "filtered_by_policy",
+			"event_name": j.eventName,
+			"repo":       j.event.Repository(),
+		}).Debug()
+		return
+	}
+
+	if wp.threads.Hold(j) {
+		return
+	}
+
+	wp.announce(j)
+}
+
+// announce renders the event and sends it to the repo's dispatcher.
+func (wp *workerPool) announce(j job) {
+	logger := wp.deps.logger
+	defer wp.recover(j)
+
+	summary, err := eventMessage(j.ctx, j.eventName, j.event, logger)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"event":      "failed_event_message",
+			"error":      err,
+			"event_name": j.eventName,
+		}).Error("couldn't build event message")
+		return
+	}
+
+	if len(summary) < 1 {
+		return
+	}
+
+	repo, channel := eventDestination(j.event)
+	err = wp.deps.dispatchers.ProcessChannelMessage(repo, channel, summary, logger)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"error":   err,
+			"payload": summary,
+		}).Error("error sending message")
+	}
+}
+
+func (wp *workerPool) recover(j job) {
+	if r := recover(); r != nil {
+		wp.deps.logger.WithFields(logrus.Fields{
+			"event":      "ErrPanicked",
+			"error":      r,
+			"event_name": j.eventName,
+		}).Error("panic recovered processing event")
+	}
+}